@@ -1,7 +1,6 @@
 package files
 
 import (
-	"bufio"
 	"bytes"
 	"os"
 	"regexp"
@@ -46,32 +45,7 @@ func WriteEDNFile(filePath string, content interface{}) error {
 }
 
 func ReadNDEDNFile[T any](filePath string) (*FileContent[[]T], error) {
-	fileInfo, err := ReadFileInfo(filePath)
-	if err != nil {
-		return nil, err
-	}
-
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	scanner := bufio.NewScanner(file)
-
-	var content []T
-
-	for scanner.Scan() {
-		var contentLine T
-		err = edn.Unmarshal([]byte(scanner.Text()), &contentLine)
-		if err != nil {
-			return nil, err
-		}
-		content = append(content, contentLine)
-	}
-
-	return &FileContent[[]T]{
-		FileInfo: *fileInfo,
-		Content:  content,
-	}, nil
+	return readNDFile(filePath, NewNDEDNDecoder[T])
 }
 
 func ReadNDEDNDirs[T any](dirNames []string, regex *regexp.Regexp) ([]FileContent[[]T], error) {
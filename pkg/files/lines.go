@@ -0,0 +1,141 @@
+package files
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"olympos.io/encoding/edn"
+)
+
+type UnmarshalFn func(data []byte, v any) error
+
+// LineError is returned by LineDecoder.Decode for a line that could not be
+// unmarshalled, decoding can go on with the next line.
+type LineError struct {
+	Line   int
+	Offset int64
+	Err    error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d (offset %d): %s", e.Line, e.Offset, e.Err.Error())
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+type LineDecoder[T any] struct {
+	reader    *bufio.Reader
+	unmarshal UnmarshalFn
+	line      int
+	offset    int64
+	next      int64
+}
+
+func NewLineDecoder[T any](r io.Reader, unmarshal UnmarshalFn) *LineDecoder[T] {
+	return &LineDecoder[T]{
+		reader:    bufio.NewReader(r),
+		unmarshal: unmarshal,
+	}
+}
+
+func NewNDJSONDecoder[T any](r io.Reader) *LineDecoder[T] {
+	return NewLineDecoder[T](r, json.Unmarshal)
+}
+
+func NewNDEDNDecoder[T any](r io.Reader) *LineDecoder[T] {
+	return NewLineDecoder[T](r, edn.Unmarshal)
+}
+
+func NewNDXMLDecoder[T any](r io.Reader) *LineDecoder[T] {
+	return NewLineDecoder[T](r, xml.Unmarshal)
+}
+
+// Line is the 1-based line number of the last decoded element.
+func (d *LineDecoder[T]) Line() int {
+	return d.line
+}
+
+// Offset is the byte offset where the line of the last decoded element starts.
+func (d *LineDecoder[T]) Offset() int64 {
+	return d.offset
+}
+
+// Decode reads the next non blank line into dest, returning io.EOF when the
+// input ends and a *LineError when the line content is invalid.
+func (d *LineDecoder[T]) Decode(dest *T) error {
+	for {
+		data, err := d.reader.ReadBytes('\n')
+		if len(data) == 0 && err != nil {
+			if err == io.EOF {
+				return io.EOF
+			}
+			return errors.Wrap(err, "failed to read line")
+		}
+		if err != nil && err != io.EOF {
+			return errors.Wrap(err, "failed to read line")
+		}
+		d.line++
+		d.offset = d.next
+		d.next += int64(len(data))
+
+		data = bytes.TrimSpace(data)
+		if len(data) == 0 {
+			continue
+		}
+		var content T
+		err = d.unmarshal(data, &content)
+		if err != nil {
+			return &LineError{
+				Line:   d.line,
+				Offset: d.offset,
+				Err:    err,
+			}
+		}
+		*dest = content
+		return nil
+	}
+}
+
+func readNDFile[T any](
+	filePath string,
+	newDecoder func(r io.Reader) *LineDecoder[T],
+) (*FileContent[[]T], error) {
+	fileInfo, err := ReadFileInfo(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	decoder := newDecoder(file)
+
+	var content []T
+
+	for {
+		var contentLine T
+		err = decoder.Decode(&contentLine)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		content = append(content, contentLine)
+	}
+
+	return &FileContent[[]T]{
+		FileInfo: *fileInfo,
+		Content:  content,
+	}, nil
+}
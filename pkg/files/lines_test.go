@@ -0,0 +1,46 @@
+package files
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+)
+
+func Test_LineDecoder(t *testing.T) {
+	decoder := NewNDJSONDecoder[someType](strings.NewReader(
+		"{\"id\":1,\"value\":\"a\"}\n" +
+			"\n" +
+			"{\"id\":\"x\"}\r\n" +
+			"{\"id\":3,\"value\":\"c\"}",
+	))
+
+	var item someType
+	err := decoder.Decode(&item)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, someType{ID: 1, Value: "a"}, item)
+	assert.Equal(t, 1, decoder.Line())
+	assert.Equal(t, int64(0), decoder.Offset())
+
+	err = decoder.Decode(&item)
+	lineErr, ok := err.(*LineError)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, 3, lineErr.Line)
+	assert.Equal(t, int64(22), lineErr.Offset)
+
+	err = decoder.Decode(&item)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, someType{ID: 3, Value: "c"}, item)
+	assert.Equal(t, 4, decoder.Line())
+	assert.Equal(t, int64(34), decoder.Offset())
+
+	assert.Equal(t, io.EOF, decoder.Decode(&item))
+}
@@ -1,7 +1,6 @@
 package files
 
 import (
-	"bufio"
 	"bytes"
 	"encoding/xml"
 	"os"
@@ -46,32 +45,7 @@ func WriteXMLFile(filePath string, content interface{}) error {
 }
 
 func ReadNDXMLFile[T any](filePath string) (*FileContent[[]T], error) {
-	fileInfo, err := ReadFileInfo(filePath)
-	if err != nil {
-		return nil, err
-	}
-
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, err
-	}
-	scanner := bufio.NewScanner(file)
-
-	var content []T
-
-	for scanner.Scan() {
-		var contentLine T
-		err = xml.Unmarshal([]byte(scanner.Text()), &contentLine)
-		if err != nil {
-			return nil, err
-		}
-		content = append(content, contentLine)
-	}
-
-	return &FileContent[[]T]{
-		FileInfo: *fileInfo,
-		Content:  content,
-	}, nil
+	return readNDFile(filePath, NewNDXMLDecoder[T])
 }
 
 func ReadNDXMLDirs[T any](dirNames []string, regex *regexp.Regexp) ([]FileContent[[]T], error) {
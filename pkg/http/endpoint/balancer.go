@@ -0,0 +1,207 @@
+package endpoint
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+	"github.com/vitorsss/go-helpers/pkg/logs"
+)
+
+type BalancerStrategy int
+
+const (
+	BalanceRoundRobin BalancerStrategy = iota
+	BalanceRandom
+	BalanceLeastInFlight
+	BalanceFailover
+)
+
+type BalancerConfig struct {
+	Strategy BalancerStrategy
+	// FailureThreshold is the number of consecutive failures that ejects a host.
+	FailureThreshold int
+	BaseEjection     time.Duration
+	MaxEjection      time.Duration
+}
+
+type balancedHost struct {
+	url          string
+	inFlight     int
+	failures     int
+	ejections    int
+	ejectedUntil time.Time
+}
+
+type balancer struct {
+	config BalancerConfig
+	now    func() time.Time
+	lock   sync.Mutex
+	hosts  []*balancedHost
+	next   int
+}
+
+func (b *balancer) acquire() *balancedHost {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	healthy := make([]*balancedHost, 0, len(b.hosts))
+	for _, host := range b.hosts {
+		if !now.Before(host.ejectedUntil) {
+			healthy = append(healthy, host)
+		}
+	}
+	if len(healthy) == 0 {
+		// with every host ejected the one coming back first is tried anyway
+		soonest := b.hosts[0]
+		for _, host := range b.hosts[1:] {
+			if host.ejectedUntil.Before(soonest.ejectedUntil) {
+				soonest = host
+			}
+		}
+		healthy = append(healthy, soonest)
+	}
+
+	var host *balancedHost
+	switch b.config.Strategy {
+	case BalanceRandom:
+		host = healthy[rand.Intn(len(healthy))]
+	case BalanceLeastInFlight:
+		host = healthy[0]
+		for _, candidate := range healthy[1:] {
+			if candidate.inFlight < host.inFlight {
+				host = candidate
+			}
+		}
+	case BalanceFailover:
+		host = healthy[0]
+	default:
+		host = healthy[b.next%len(healthy)]
+		b.next++
+	}
+	host.inFlight++
+	return host
+}
+
+func (b *balancer) ejection(ejections int) time.Duration {
+	ejection := b.config.BaseEjection
+	for idx := 1; idx < ejections && ejection < b.config.MaxEjection; idx++ {
+		ejection *= 2
+	}
+	if ejection > b.config.MaxEjection {
+		return b.config.MaxEjection
+	}
+	return ejection
+}
+
+func (b *balancer) report(host *balancedHost, res *http.Response, err error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+		return
+	}
+	if err == nil && res.StatusCode < http.StatusInternalServerError {
+		host.failures = 0
+		host.ejections = 0
+		return
+	}
+	host.failures++
+	if host.failures >= b.config.FailureThreshold {
+		host.failures = 0
+		host.ejections++
+		host.ejectedUntil = b.now().Add(b.ejection(host.ejections))
+	}
+}
+
+func (b *balancer) release(host *balancedHost) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	host.inFlight--
+}
+
+// balancedBody keeps the host in flight until the response body is closed.
+type balancedBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *balancedBody) Close() error {
+	b.once.Do(b.release)
+	return b.ReadCloser.Close()
+}
+
+func (b *balancer) done(host *balancedHost, res *http.Response, err error) *http.Response {
+	b.report(host, res, err)
+	if res == nil || res.Body == nil {
+		b.release(host)
+		return res
+	}
+	res.Body = &balancedBody{
+		ReadCloser: res.Body,
+		release: func() {
+			b.release(host)
+		},
+	}
+	return res
+}
+
+// NewBalancedEndpoint spreads the calls over every base URI, hosts failing with
+// transport errors or 5xx responses are ejected for an exponential period.
+// Combined with WithRetry each attempt may land on a different host.
+func NewBalancedEndpoint(
+	baseURIs []string,
+	pathURI string,
+	requester requester.Requester,
+	config BalancerConfig,
+	options ...EndpointOption,
+) Endpoint {
+	if len(baseURIs) == 0 {
+		err := errors.New("endpoint: balanced endpoint without base URIs")
+		logs.Logger.Error().Err(err).Send()
+		panic(err)
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 1
+	}
+	if config.BaseEjection <= 0 {
+		config.BaseEjection = time.Second
+	}
+	if config.MaxEjection < config.BaseEjection {
+		config.MaxEjection = 30 * config.BaseEjection
+	}
+
+	b := &balancer{
+		config: config,
+		now:    time.Now,
+	}
+	for _, baseURI := range baseURIs {
+		urlStr, err := joinURL(baseURI, pathURI)
+		if err != nil {
+			logs.Logger.Error().Err(err).Send()
+			panic(err)
+		}
+
+		err = validateURLParams(urlStr)
+		if err != nil {
+			logs.Logger.Error().Err(err).Send()
+			panic(err)
+		}
+		b.hosts = append(b.hosts, &balancedHost{
+			url: urlStr,
+		})
+	}
+
+	return &endpoint{
+		BaseOptions: options,
+		Requester:   requester,
+		URL:         b.hosts[0].url,
+		balancer:    b,
+	}
+}
@@ -0,0 +1,188 @@
+package endpoint
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+)
+
+type hostsRequester struct {
+	lock     sync.Mutex
+	statuses map[string]int
+	urls     []string
+}
+
+func (r *hostsRequester) Do(req *http.Request) (*http.Response, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.urls = append(r.urls, req.URL.String())
+	status, ok := r.statuses[req.URL.Host]
+	if !ok {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+	}, nil
+}
+
+func (r *hostsRequester) calls() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	calls := r.urls
+	r.urls = nil
+	return calls
+}
+
+func Test_BalancedEndpoint_RoundRobin(t *testing.T) {
+	hosts := &hostsRequester{}
+	end := NewBalancedEndpoint(
+		[]string{"http://a.example", "http://b.example"},
+		"/api/v1/as/{paramA}",
+		hosts,
+		BalancerConfig{Strategy: BalanceRoundRobin},
+	)
+
+	for idx := 0; idx < 3; idx++ {
+		res, err := end.Get(context.Background(), WithParam("paramA", "some_value"))
+		if !assertutil.Error(t, nil, err) {
+			return
+		}
+		_ = res.Close()
+	}
+	assert.Equal(t, []string{
+		"http://a.example/api/v1/as/some_value",
+		"http://b.example/api/v1/as/some_value",
+		"http://a.example/api/v1/as/some_value",
+	}, hosts.calls())
+}
+
+func Test_BalancedEndpoint_Failover(t *testing.T) {
+	hosts := &hostsRequester{
+		statuses: map[string]int{
+			"a.example": http.StatusServiceUnavailable,
+		},
+	}
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	end := NewBalancedEndpoint(
+		[]string{"http://a.example", "http://b.example"},
+		"/items",
+		hosts,
+		BalancerConfig{
+			Strategy:     BalanceFailover,
+			BaseEjection: time.Minute,
+			MaxEjection:  time.Hour,
+		},
+		WithRetry(policy),
+	)
+	now := time.Now()
+	end.(*endpoint).balancer.now = func() time.Time {
+		return now
+	}
+
+	res, err := end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	_ = res.Close()
+	assert.Equal(t, []string{
+		"http://a.example/items",
+		"http://b.example/items",
+	}, hosts.calls())
+
+	res, err = end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	_ = res.Close()
+	assert.Equal(t, []string{"http://b.example/items"}, hosts.calls())
+
+	// the second ejection doubles the period
+	now = now.Add(time.Minute)
+	res, err = end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	_ = res.Close()
+	assert.Equal(t, []string{
+		"http://a.example/items",
+		"http://b.example/items",
+	}, hosts.calls())
+	host := end.(*endpoint).balancer.hosts[0]
+	assert.Equal(t, now.Add(2*time.Minute), host.ejectedUntil)
+
+	hosts.statuses = nil
+	now = now.Add(2 * time.Minute)
+	res, err = end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	_ = res.Close()
+	assert.Equal(t, []string{"http://a.example/items"}, hosts.calls())
+	assert.Equal(t, 0, host.ejections)
+}
+
+func Test_BalancedEndpoint_LeastInFlight(t *testing.T) {
+	hosts := &hostsRequester{}
+	end := NewBalancedEndpoint(
+		[]string{"http://a.example", "http://b.example"},
+		"/items",
+		hosts,
+		BalancerConfig{Strategy: BalanceLeastInFlight},
+	)
+
+	first, err := end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	second, err := end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	_ = second.Close()
+	third, err := end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	_ = first.Close()
+	_ = third.Close()
+
+	assert.Equal(t, []string{
+		"http://a.example/items",
+		"http://b.example/items",
+		"http://b.example/items",
+	}, hosts.calls())
+}
+
+func Test_BalancedEndpoint_RejectedResponses(t *testing.T) {
+	hosts := &hostsRequester{
+		statuses: map[string]int{
+			"a.example": http.StatusBadRequest,
+			"b.example": http.StatusBadRequest,
+		},
+	}
+	end := NewBalancedEndpoint(
+		[]string{"http://a.example", "http://b.example"},
+		"/items",
+		hosts,
+		BalancerConfig{Strategy: BalanceLeastInFlight},
+	)
+
+	for idx := 0; idx < 3; idx++ {
+		_, err := end.Get(context.Background())
+		_, ok := AsHTTPError(err)
+		assert.True(t, ok)
+	}
+	for _, host := range end.(*endpoint).balancer.hosts {
+		assert.Equal(t, 0, host.inFlight)
+	}
+}
@@ -32,6 +32,7 @@ func (o *withRawBodyEndpointOption) apply(
 		return o.err
 	}
 	opts.body = o.body
+	opts.pendingBody = nil
 	if o.contentType != "" {
 		if opts.headers == nil {
 			opts.headers = http.Header{}
@@ -72,3 +73,54 @@ func WithFormURLEncodedBody(content url.Values) EndpointOption {
 		body:        strings.NewReader(content.Encode()),
 	}
 }
+
+type pendingBody struct {
+	contentType string
+	content     any
+}
+
+func (o *endpointOptions) encodePendingBody() error {
+	if o.pendingBody == nil {
+		return nil
+	}
+	codec, err := lookupCodec(o.codecs, o.pendingBody.contentType)
+	if err != nil {
+		return err
+	}
+	buffer := bytes.NewBuffer([]byte{})
+	err = codec.Encode(buffer, o.pendingBody.content)
+	if err != nil {
+		return errors.Wrapf(err, "failed to encode %s body", o.pendingBody.contentType)
+	}
+	o.body = bytes.NewReader(buffer.Bytes())
+	o.pendingBody = nil
+	return nil
+}
+
+type withBodyEndpointOption struct {
+	contentType string
+	content     any
+}
+
+func (o *withBodyEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	opts.body = nil
+	opts.pendingBody = &pendingBody{
+		contentType: o.contentType,
+		content:     o.content,
+	}
+	if opts.headers == nil {
+		opts.headers = http.Header{}
+	}
+	opts.headers.Set("Content-Type", o.contentType)
+	return nil
+}
+
+func WithBody(contentType string, content any) EndpointOption {
+	return &withBodyEndpointOption{
+		contentType: contentType,
+		content:     content,
+	}
+}
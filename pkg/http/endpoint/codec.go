@@ -0,0 +1,267 @@
+package endpoint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"olympos.io/encoding/edn"
+)
+
+type Codec interface {
+	Encode(w io.Writer, v any) error
+	Decode(r io.Reader, v any) error
+}
+
+type marshalCodec struct {
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
+}
+
+func (c *marshalCodec) Encode(w io.Writer, v any) error {
+	data, err := c.marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+func (c *marshalCodec) Decode(r io.Reader, v any) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+	return c.unmarshal(data, v)
+}
+
+func MarshalCodec(
+	marshal func(v any) ([]byte, error),
+	unmarshal func(data []byte, v any) error,
+) Codec {
+	return &marshalCodec{
+		marshal:   marshal,
+		unmarshal: unmarshal,
+	}
+}
+
+type CodecRegistry struct {
+	lock   sync.RWMutex
+	codecs map[string]Codec
+}
+
+func NewCodecRegistry() *CodecRegistry {
+	return &CodecRegistry{
+		codecs: map[string]Codec{},
+	}
+}
+
+func (r *CodecRegistry) Register(mediaType string, codec Codec) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.codecs[strings.ToLower(mediaType)] = codec
+}
+
+func (r *CodecRegistry) Lookup(mediaType string) (Codec, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for _, candidate := range mediaTypeCandidates(mediaType) {
+		if codec, ok := r.codecs[candidate]; ok {
+			return codec, true
+		}
+	}
+	return nil, false
+}
+
+var DefaultCodecRegistry = newDefaultCodecRegistry()
+
+func RegisterCodec(mediaType string, codec Codec) {
+	DefaultCodecRegistry.Register(mediaType, codec)
+}
+
+func newDefaultCodecRegistry() *CodecRegistry {
+	registry := NewCodecRegistry()
+	jsonCodec := MarshalCodec(json.Marshal, json.Unmarshal)
+	ednCodec := MarshalCodec(edn.Marshal, edn.Unmarshal)
+	xmlCodec := MarshalCodec(xml.Marshal, xml.Unmarshal)
+	registry.Register("application/json", jsonCodec)
+	registry.Register("application/edn", ednCodec)
+	registry.Register("application/xml", xmlCodec)
+	registry.Register("text/xml", xmlCodec)
+	registry.Register("application/transit+json", &transitCodec{})
+	registry.Register("application/x-ndjson", &ndjsonCodec{})
+	registry.Register("application/ndjson", &ndjsonCodec{})
+	registry.Register("application/x-ndedn", &ndednCodec{})
+	registry.Register("text/csv", &csvCodec{})
+	registry.Register("application/x-www-form-urlencoded", &formCodec{})
+	registry.Register("application/x-gzip", &gzipCodec{})
+	return registry
+}
+
+// mediaTypeCandidates lists the media type itself followed by the structured
+// syntax suffix fallback, so application/vnd.api+json resolves to application/json.
+func mediaTypeCandidates(mediaType string) []string {
+	mediaType = strings.ToLower(mediaType)
+	candidates := []string{mediaType}
+	if idx := strings.LastIndex(mediaType, "+"); idx >= 0 && idx < len(mediaType)-1 {
+		candidates = append(candidates, "application/"+mediaType[idx+1:])
+	}
+	return candidates
+}
+
+func lookupCodec(codecs map[string]Codec, contentType string) (Codec, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse media type for content-type - %s", contentType)
+	}
+	for _, candidate := range mediaTypeCandidates(mediaType) {
+		if codec, ok := codecs[candidate]; ok {
+			return codec, nil
+		}
+	}
+	codec, ok := DefaultCodecRegistry.Lookup(mediaType)
+	if !ok {
+		return nil, errors.Wrapf(ErrUnmappedMediaType, "unmapped - %s", mediaType)
+	}
+	return codec, nil
+}
+
+type withCodecEndpointOption struct {
+	mediaType string
+	codec     Codec
+}
+
+func (o *withCodecEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	if opts.codecs == nil {
+		opts.codecs = map[string]Codec{}
+	}
+	opts.codecs[strings.ToLower(o.mediaType)] = o.codec
+	return nil
+}
+
+func WithCodec(mediaType string, codec Codec) EndpointOption {
+	return &withCodecEndpointOption{
+		mediaType: mediaType,
+		codec:     codec,
+	}
+}
+
+func sliceTarget(v any) (reflect.Value, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return reflect.Value{}, errors.Wrap(ErrInvalidUnmarshalTarget, "expected pointer to slice")
+	}
+	return rv.Elem(), nil
+}
+
+type valueDecoder interface {
+	Decode(v any) error
+}
+
+func decodeValues(decoder valueDecoder, v any) error {
+	slice, err := sliceTarget(v)
+	if err != nil {
+		return err
+	}
+	elemType := slice.Type().Elem()
+	result := reflect.MakeSlice(slice.Type(), 0, 0)
+	for {
+		elem := reflect.New(elemType)
+		err := decoder.Decode(elem.Interface())
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		result = reflect.Append(result, elem.Elem())
+	}
+	slice.Set(result)
+	return nil
+}
+
+func encodeValues(w io.Writer, v any, marshal func(v any) ([]byte, error)) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return errors.New("endpoint: expected slice to encode line delimited content")
+	}
+	for idx := 0; idx < rv.Len(); idx++ {
+		data, err := marshal(rv.Index(idx).Interface())
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(data, '\n'))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type ndjsonCodec struct{}
+
+func (c *ndjsonCodec) Encode(w io.Writer, v any) error {
+	return encodeValues(w, v, json.Marshal)
+}
+
+func (c *ndjsonCodec) Decode(r io.Reader, v any) error {
+	return decodeValues(json.NewDecoder(r), v)
+}
+
+type ndednCodec struct{}
+
+func (c *ndednCodec) Encode(w io.Writer, v any) error {
+	return encodeValues(w, v, edn.Marshal)
+}
+
+func (c *ndednCodec) Decode(r io.Reader, v any) error {
+	return decodeValues(edn.NewDecoder(r), v)
+}
+
+type gzipCodec struct{}
+
+func (c *gzipCodec) Encode(w io.Writer, v any) error {
+	content, ok := v.([]byte)
+	if !ok {
+		return errors.Wrap(ErrInvalidUnmarshalTarget, "expected []byte to encode gzip content")
+	}
+	gzipWriter := gzip.NewWriter(w)
+	_, err := gzipWriter.Write(content)
+	if err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+func (c *gzipCodec) Decode(r io.Reader, v any) error {
+	dest, ok := v.(*[]byte)
+	if !ok {
+		return errors.Wrap(ErrInvalidUnmarshalTarget, "unmapped")
+	}
+	reader, release, err := acquireGzipReader(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+	defer release()
+	buffer := bytes.NewBuffer(*dest)
+	_, err = io.Copy(buffer, reader)
+	if err != nil {
+		return errors.Wrap(err, "failed to read gziped content")
+	}
+	*dest = buffer.Bytes()
+	return nil
+}
@@ -0,0 +1,175 @@
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/httptest"
+)
+
+func Test_CodecRegistry_Lookup(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		ok        bool
+	}{
+		{
+			name:      "should find exact media type",
+			mediaType: "application/edn",
+			ok:        true,
+		},
+		{
+			name:      "should find json suffix media type",
+			mediaType: "application/vnd.api+json",
+			ok:        true,
+		},
+		{
+			name:      "should find edn suffix media type",
+			mediaType: "application/vnd.some+edn",
+			ok:        true,
+		},
+		{
+			name:      "should not find unmapped media type",
+			mediaType: "application/octet-stream",
+			ok:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := DefaultCodecRegistry.Lookup(tt.mediaType)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func Test_Codecs_Decode(t *testing.T) {
+	type transitType struct {
+		ID     int      `json:"id"`
+		Status string   `json:"status"`
+		Tags   []string `json:"tags"`
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		dest        any
+		expected    any
+	}{
+		{
+			name:        "should decode transit with cached keys",
+			contentType: "application/transit+json",
+			body:        `[["^ ","~:id",1,"~:status","~:active","~:tags",["~#set",["~:a"]]],["^ ","^0",2,"^1","^2","^3",["^4",["~~b"]]]]`,
+			dest:        &[]transitType{},
+			expected: &[]transitType{
+				{ID: 1, Status: "active", Tags: []string{"a"}},
+				{ID: 2, Status: "active", Tags: []string{"~b"}},
+			},
+		},
+		{
+			name:        "should decode ndjson",
+			contentType: "application/x-ndjson",
+			body:        "{\"id\":1}\n{\"id\":2}\n",
+			dest:        &[]someType{},
+			expected:    &[]someType{{ID: 1}, {ID: 2}},
+		},
+		{
+			name:        "should decode ndedn",
+			contentType: "application/x-ndedn",
+			body:        "{:id 1}\n{:id 2}\n",
+			dest:        &[]someType{},
+			expected:    &[]someType{{ID: 1}, {ID: 2}},
+		},
+		{
+			name:        "should decode csv with headers",
+			contentType: "text/csv; charset=utf-8",
+			body:        "id,name\n1,a\n",
+			dest:        &[]map[string]string{},
+			expected:    &[]map[string]string{{"id": "1", "name": "a"}},
+		},
+		{
+			name:        "should decode form urlencoded",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "a=1&b=2&b=3",
+			dest:        &url.Values{},
+			expected:    &url.Values{"a": {"1"}, "b": {"2", "3"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codec, err := lookupCodec(nil, tt.contentType)
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			err = codec.Decode(strings.NewReader(tt.body), tt.dest)
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, tt.dest)
+		})
+	}
+}
+
+func Test_transitCodec_Encode(t *testing.T) {
+	buffer := bytes.NewBuffer([]byte{})
+	err := (&transitCodec{}).Encode(buffer, map[string]any{
+		"id":   1,
+		"name": "~tilde",
+	})
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, `["^ ","id",1,"name","~~tilde"]`+"\n", buffer.String())
+}
+
+func Test_Endpoint_WithBody(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as",
+		server.Requester(),
+		WithCodec("application/vnd.custom+xml", MarshalCodec(
+			func(v any) ([]byte, error) {
+				return []byte("custom"), nil
+			},
+			func(data []byte, v any) error {
+				*(v.(*string)) = string(data)
+				return nil
+			},
+		)),
+	)
+
+	server.
+		Header(http.Header{
+			"Content-Type": []string{"application/vnd.custom+xml"},
+		}).
+		Body([]byte(`custom`)).
+		Post("/api/v1/as").
+		Return(
+			200,
+			[]byte(`response`),
+			http.Header{
+				"Content-Type": []string{"application/vnd.custom+xml"},
+			},
+		)
+
+	response, err := end.Post(context.Background(),
+		WithBody("application/vnd.custom+xml", someType{ID: 42}),
+	)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	result := ""
+	err = response.Unmarshal(&result)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, "response", result)
+}
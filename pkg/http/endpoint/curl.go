@@ -0,0 +1,50 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+type curlCommand struct {
+	redactor *requester.Redactor
+	fn       func(command string)
+}
+
+func (c *curlCommand) wrap(client requester.Requester) requester.Requester {
+	return requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		command, err := requester.CurlCommand(req, c.redactor)
+		if err != nil {
+			return nil, err
+		}
+		c.fn(command)
+		return client.Do(req)
+	})
+}
+
+type withCurlCommandEndpointOption struct {
+	curl *curlCommand
+}
+
+func (o *withCurlCommandEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	opts.curlCommand = o.curl
+	return nil
+}
+
+// WithCurlCommand calls fn with every request sent by the call, including the
+// ones made by auth, rendered as a curl command.
+func WithCurlCommand(
+	redactor *requester.Redactor,
+	fn func(command string),
+) EndpointOption {
+	return &withCurlCommandEndpointOption{
+		curl: &curlCommand{
+			redactor: redactor,
+			fn:       fn,
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/httptest"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+func Test_Endpoint_WithCurlCommand(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as/{paramA}",
+		server.Requester(),
+		WithBearerTokenAuth("token"),
+	)
+
+	server.
+		Body([]byte(`{"name":"a"}`)).
+		Post("/api/v1/as/some_value").
+		Return(200, nil, http.Header{})
+
+	commands := []string{}
+	ctx := ContextWithRequestID(context.Background(), "request-1")
+	_, err := end.Post(ctx,
+		WithParam("paramA", "some_value"),
+		WithBody("application/json", map[string]string{"name": "a"}),
+		WithCurlCommand(requester.DefaultRedactor(), func(command string) {
+			commands = append(commands, command)
+		}),
+	)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, []string{
+		"curl -X POST '" + server.BaseURL() + "/api/v1/as/some_value' " +
+			"-H 'Authorization: [REDACTED]' " +
+			"-H 'Content-Type: application/json' " +
+			"-H 'X-Request-Id: request-1' " +
+			`--data-raw '{"name":"a"}'`,
+	}, commands)
+}
@@ -0,0 +1,221 @@
+package endpoint
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var ErrUnsupportedContentEncoding = errors.New("endpoint: unsupported content encoding")
+
+const (
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+type decodingBody struct {
+	body     io.ReadCloser
+	encoding string
+	reader   io.Reader
+	release  func()
+	err      error
+}
+
+func (b *decodingBody) init() {
+	switch b.encoding {
+	case EncodingGzip, "x-gzip":
+		reader, release, err := acquireGzipReader(b.body)
+		if errors.Is(err, io.EOF) {
+			b.reader = bytes.NewReader([]byte{})
+			return
+		}
+		if err != nil {
+			b.err = errors.Wrap(err, "failed to read gziped content")
+			return
+		}
+		b.reader = reader
+		b.release = release
+	case EncodingDeflate:
+		buffered := bufio.NewReader(b.body)
+		header, err := buffered.Peek(2)
+		if errors.Is(err, io.EOF) && len(header) == 0 {
+			b.reader = bytes.NewReader([]byte{})
+			return
+		}
+		// servers disagree on "deflate", so zlib wrapped and raw streams are both accepted
+		if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			reader, err := zlib.NewReader(buffered)
+			if err != nil {
+				b.err = errors.Wrap(err, "failed to read deflated content")
+				return
+			}
+			b.reader = reader
+			return
+		}
+		b.reader = flate.NewReader(buffered)
+	}
+}
+
+func (b *decodingBody) Read(p []byte) (int, error) {
+	if b.reader == nil && b.err == nil {
+		b.init()
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.reader.Read(p)
+}
+
+func (b *decodingBody) Close() error {
+	if b.release != nil {
+		b.release()
+		b.release = nil
+	}
+	return b.body.Close()
+}
+
+func decodeContentEncoding(res *http.Response) *http.Response {
+	encoding := strings.ToLower(strings.TrimSpace(res.Header.Get("Content-Encoding")))
+	switch encoding {
+	case EncodingGzip, "x-gzip", EncodingDeflate:
+	default:
+		return res
+	}
+	res.Body = &decodingBody{
+		body:     res.Body,
+		encoding: encoding,
+	}
+	res.Header.Del("Content-Encoding")
+	res.Header.Del("Content-Length")
+	res.ContentLength = -1
+	res.Uncompressed = true
+	return res
+}
+
+type withRequestCompressionEndpointOption struct {
+	encoding string
+}
+
+func (o *withRequestCompressionEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	switch o.encoding {
+	case EncodingGzip, EncodingDeflate:
+	default:
+		return errors.Wrapf(ErrUnsupportedContentEncoding, "request - %s", o.encoding)
+	}
+	opts.bodyEncoding = o.encoding
+	return nil
+}
+
+func WithRequestCompression(encoding string) EndpointOption {
+	return &withRequestCompressionEndpointOption{
+		encoding: strings.ToLower(encoding),
+	}
+}
+
+func (o *endpointOptions) compressBody() error {
+	if o.bodyEncoding == "" || o.body == nil {
+		return nil
+	}
+	o.body = &compressedBody{
+		source:   o.body,
+		encoding: o.bodyEncoding,
+	}
+	if o.headers == nil {
+		o.headers = http.Header{}
+	}
+	o.headers.Set("Content-Encoding", o.bodyEncoding)
+	return nil
+}
+
+// compressedBody compresses its source through a pipe while it is read, so
+// streaming bodies are never held in memory. Like multipartBody it only
+// supports seeking back to the start, which restarts the compression.
+type compressedBody struct {
+	source   io.Reader
+	encoding string
+	reader   *io.PipeReader
+	done     chan struct{}
+	started  bool
+}
+
+func (b *compressedBody) compress(w io.Writer) error {
+	var writer io.WriteCloser
+	switch b.encoding {
+	case EncodingGzip:
+		writer = gzip.NewWriter(w)
+	case EncodingDeflate:
+		writer = zlib.NewWriter(w)
+	}
+	_, err := io.Copy(writer, b.source)
+	if err != nil {
+		return errors.Wrap(err, "failed to compress body")
+	}
+	return errors.Wrap(writer.Close(), "failed to compress body")
+}
+
+func (b *compressedBody) Read(p []byte) (int, error) {
+	if b.reader == nil {
+		pipeReader, pipeWriter := io.Pipe()
+		done := make(chan struct{})
+		b.reader = pipeReader
+		b.done = done
+		b.started = true
+		go func() {
+			defer close(done)
+			_ = pipeWriter.CloseWithError(b.compress(pipeWriter))
+		}()
+	}
+	return b.reader.Read(p)
+}
+
+// Close stops the compression when the body is not read to the end.
+func (b *compressedBody) Close() error {
+	if b.reader != nil {
+		_ = b.reader.CloseWithError(io.ErrClosedPipe)
+		<-b.done
+		b.reader = nil
+	}
+	if body, ok := b.source.(*multipartBody); ok {
+		return body.Close()
+	}
+	return nil
+}
+
+// replayable reports whether the body can be sent again, which for sources
+// that can not seek is only before it was first read.
+func (b *compressedBody) replayable() bool {
+	if !b.started {
+		return true
+	}
+	return isSeeker(b.source) && isReplayable(b.source)
+}
+
+func (b *compressedBody) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekStart {
+		return 0, errors.New("endpoint: compressed body only supports seeking to start")
+	}
+	_ = b.Close()
+	seeker, ok := b.source.(io.Seeker)
+	if !ok {
+		if b.started {
+			return 0, errors.Wrap(ErrUnreadableBody, "compressed body")
+		}
+		return 0, nil
+	}
+	_, err := seeker.Seek(0, io.SeekStart)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to reset body reader")
+	}
+	return 0, nil
+}
@@ -0,0 +1,196 @@
+package endpoint
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/httptest"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+func gzipContent(content []byte) []byte {
+	buffer := bytes.NewBuffer([]byte{})
+	writer := gzip.NewWriter(buffer)
+	_, _ = writer.Write(content)
+	_ = writer.Close()
+	return buffer.Bytes()
+}
+
+func flateContent(content []byte) []byte {
+	buffer := bytes.NewBuffer([]byte{})
+	writer, _ := flate.NewWriter(buffer, flate.DefaultCompression)
+	_, _ = writer.Write(content)
+	_ = writer.Close()
+	return buffer.Bytes()
+}
+
+func Test_Endpoint_ContentEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{
+			name:     "should decode gzip content encoding",
+			encoding: "gzip",
+			body:     gzipContent([]byte(`{"id":42}`)),
+		},
+		{
+			name:     "should decode raw deflate content encoding",
+			encoding: "deflate",
+			body:     flateContent([]byte(`{"id":42}`)),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.New(t)
+			end := NewEndpoint(
+				server.BaseURL(),
+				"/api/v1/as",
+				server.Requester(),
+			)
+
+			server.
+				Get("/api/v1/as").
+				Return(
+					200,
+					tt.body,
+					http.Header{
+						"Content-Type":     []string{"application/json"},
+						"Content-Encoding": []string{tt.encoding},
+					},
+				)
+
+			response, err := end.Get(context.Background())
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			assert.Empty(t, response.Headers().Get("Content-Encoding"))
+
+			result := someType{}
+			err = response.Unmarshal(&result)
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			assert.Equal(t, someType{ID: 42}, result)
+			assert.Nil(t, response.Close())
+		})
+	}
+}
+
+func Test_Endpoint_WithRequestCompression(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as",
+		server.Requester(),
+		WithRequestCompression(EncodingGzip),
+	)
+
+	server.
+		Header(http.Header{
+			"Content-Encoding": []string{"gzip"},
+		}).
+		Body(gzipContent([]byte(`{"id":42}`))).
+		Post("/api/v1/as").
+		DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			reader, err := gzip.NewReader(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body:       io.NopCloser(bytes.NewReader(content)),
+				Header: http.Header{
+					"Content-Type": []string{"application/json"},
+				},
+			}, nil
+		})
+
+	result, _, err := PostAs[someType](context.Background(), end,
+		WithJSONBody(someType{ID: 42}),
+	)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, someType{ID: 42}, result)
+}
+
+// sendGatedReader fails reads made before the request reaches the server.
+type sendGatedReader struct {
+	reader *strings.Reader
+	sent   *bool
+}
+
+func (r *sendGatedReader) Read(p []byte) (int, error) {
+	if !*r.sent {
+		return 0, errors.New("read before the request was sent")
+	}
+	return r.reader.Read(p)
+}
+
+func (r *sendGatedReader) Seek(offset int64, whence int) (int64, error) {
+	return r.reader.Seek(offset, whence)
+}
+
+func Test_Endpoint_WithRequestCompression_Streaming(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+
+	sent := false
+	bodies := []string{}
+	end := NewEndpoint(
+		"http://example.com",
+		"/api/v1/uploads",
+		requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+			sent = true
+			defer req.Body.Close()
+			reader, err := gzip.NewReader(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				return nil, err
+			}
+			bodies = append(bodies, string(content))
+			status := http.StatusOK
+			if len(bodies) == 1 {
+				status = http.StatusServiceUnavailable
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}),
+		WithRequestCompression(EncodingGzip),
+		WithRetry(policy),
+	)
+
+	builder := NewMultipartBuilder().
+		File("file", "a.txt", "", &sendGatedReader{
+			reader: strings.NewReader("content"),
+			sent:   &sent,
+		})
+	res, err := end.Put(context.Background(), WithMultipartBody(builder))
+	if !assertutil.Error(t, nil, err) || !assert.Len(t, bodies, 2) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.Status())
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.Contains(t, bodies[1], "content")
+}
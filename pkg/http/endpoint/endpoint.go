@@ -3,7 +3,9 @@ package endpoint
 import (
 	"context"
 	"net/http"
+	"time"
 
+	"github.com/pkg/errors"
 	"github.com/vitorsss/go-helpers/pkg/http/requester"
 	"github.com/vitorsss/go-helpers/pkg/logs"
 )
@@ -51,6 +53,7 @@ type endpoint struct {
 	BaseOptions []EndpointOption
 	Requester   requester.Requester
 	URL         string
+	balancer    *balancer
 }
 
 func NewEndpoint(
@@ -181,41 +184,166 @@ func (e *endpoint) do(
 	if err != nil {
 		return nil, err
 	}
+	// the same correlation id is sent on every attempt of the call
+	ctx = ensureRequestID(ctx)
 
+	info := CallInfo{
+		Method:      method,
+		URLTemplate: e.URL,
+	}
+	for _, hook := range opts.hooks {
+		ctx = hook.Started(ctx, info)
+	}
+
+	start := time.Now()
+	res, err := e.call(ctx, method, opts, &info)
+	info.Duration = time.Since(start)
+	info.Err = err
+
+	for _, hook := range opts.hooks {
+		if err != nil {
+			hook.Failed(ctx, info)
+		} else {
+			hook.Finished(ctx, info)
+		}
+	}
+
+	return res, err
+}
+
+func (e *endpoint) call(
+	ctx context.Context,
+	method string,
+	opts *endpointOptions,
+	info *CallInfo,
+) (Response, error) {
+	callCtx, cancel := opts.callContext(ctx)
+	res, err := e.respond(callCtx, method, opts, info)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	res.Body = &cancelBody{
+		ReadCloser: res.Body,
+		cancel:     cancel,
+	}
+
+	// the caller context is kept so reconnections get a new timeout
+	return &response{
+		Response: res,
+		ctx:      ctx,
+		codecs:   opts.codecs,
+	}, nil
+}
+
+func (e *endpoint) respond(
+	ctx context.Context,
+	method string,
+	opts *endpointOptions,
+	info *CallInfo,
+) (*http.Response, error) {
+	res, attempts, err := e.execute(ctx, method, opts)
+	info.Attempts = attempts
+	if err != nil {
+		return nil, err
+	}
+	info.Status = res.StatusCode
+	res = decodeContentEncoding(res)
+
+	err = statusError(res, opts.statusErrors)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, errAnalyzer := range opts.errAnalyzers {
+		err = errAnalyzer(res)
+		if err != nil {
+			// closing the body frees what the requesters hold until then
+			discardBody(res)
+			if httpErr, ok := AsHTTPError(err); ok && httpErr.URLTemplate == "" {
+				httpErr.URLTemplate = e.URL
+			}
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+func (e *endpoint) attempt(
+	ctx context.Context,
+	method string,
+	opts *endpointOptions,
+) (*http.Response, error) {
+	if e.balancer == nil {
+		return e.send(ctx, method, e.URL, opts)
+	}
+	host := e.balancer.acquire()
+	res, err := e.send(ctx, method, host.url, opts)
+	return e.balancer.done(host, res, err), err
+}
+
+func (e *endpoint) send(
+	ctx context.Context,
+	method string,
+	urlTemplate string,
+	opts *endpointOptions,
+) (*http.Response, error) {
 	req, err := e.parseOptionsToRequest(
 		ctx,
 		method,
+		urlTemplate,
 		opts,
 	)
 	if err != nil {
 		return nil, err
 	}
+	mergeContextHeaders(ctx, req)
+
+	for _, hook := range opts.hooks {
+		if requestHook, ok := hook.(RequestHook); ok {
+			requestHook.BeforeRequest(req)
+		}
+	}
+
+	client := e.Requester
+	if opts.curlCommand != nil {
+		client = opts.curlCommand.wrap(client)
+	}
+	if opts.firstByteTimeout > 0 || opts.idleTimeout > 0 {
+		timeouts := &attemptTimeouts{
+			firstByte: opts.firstByteTimeout,
+			idle:      opts.idleTimeout,
+		}
+		client = timeouts.wrap(client)
+	}
 
 	var res *http.Response
 
 	if opts.authFn != nil {
-		res, err = opts.authFn(ctx, e.Requester, req)
+		res, err = opts.authFn(ctx, client, req)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	if res == nil {
-		res, err = e.Requester.Do(req)
+		if opts.authFn != nil && req.GetBody != nil {
+			// auth may have already sent or read the body, as digest challenges do
+			req.Body, err = req.GetBody()
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to reset request body")
+			}
+		}
+		res, err = client.Do(req)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	for _, errAnalyzer := range opts.errAnalyzers {
-		err = errAnalyzer(res)
-		if err != nil {
-			return nil, err
-		}
+	if res.Request == nil {
+		res.Request = req
 	}
 
-	return &response{
-		Response: res,
-		ctx:      ctx,
-	}, nil
+	return res, nil
 }
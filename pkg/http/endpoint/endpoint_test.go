@@ -2,13 +2,16 @@ package endpoint
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vitorsss/go-helpers/pkg/assertutil"
 	"github.com/vitorsss/go-helpers/pkg/http/httptest"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
 )
 
 type someType struct {
@@ -82,3 +85,27 @@ func Test_Endpoint_Get(t *testing.T) {
 
 	assert.Equal(t, 404, response.Status())
 }
+
+func Test_Endpoint_RejectedResponseReleasesBulkhead(t *testing.T) {
+	end := NewEndpoint(
+		"http://example.com",
+		"/items",
+		requester.NewBulkheadRequester(
+			requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusInternalServerError,
+					Body:       io.NopCloser(strings.NewReader("failed")),
+				}, nil
+			}),
+			1,
+		),
+	)
+
+	for idx := 0; idx < 3; idx++ {
+		_, err := end.Get(context.Background())
+		httpErr, ok := AsHTTPError(err)
+		if assert.True(t, ok) {
+			assert.Equal(t, http.StatusInternalServerError, httpErr.StatusCode)
+		}
+	}
+}
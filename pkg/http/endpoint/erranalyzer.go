@@ -34,7 +34,7 @@ func defaultErrAnalyzer(res *http.Response) error {
 		if err != nil {
 			return err
 		}
-		return errors.Errorf("endpoint: response error - %d - %s", res.StatusCode, string(content))
+		return errors.WithStack(NewHTTPError(res, content))
 	}
 	return nil
 }
@@ -51,7 +51,7 @@ func notFoundErrAnalyzer(res *http.Response) error {
 		if err != nil {
 			return err
 		}
-		return errors.Errorf("endpoint: response error - %s", string(content))
+		return errors.WithStack(NewHTTPError(res, content))
 	}
 	return nil
 }
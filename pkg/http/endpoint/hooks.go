@@ -0,0 +1,46 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type CallInfo struct {
+	Method      string
+	URLTemplate string
+	Status      int
+	Duration    time.Duration
+	Attempts    int
+	Err         error
+}
+
+type Hook interface {
+	Started(ctx context.Context, info CallInfo) context.Context
+	Finished(ctx context.Context, info CallInfo)
+	Failed(ctx context.Context, info CallInfo)
+}
+
+// RequestHook is an optional Hook extension called with every request built
+// for the call, before auth runs, so it can add propagation headers.
+type RequestHook interface {
+	BeforeRequest(req *http.Request)
+}
+
+type withHookEndpointOption struct {
+	hook Hook
+}
+
+func (o *withHookEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	opts.hooks = append(opts.hooks, o.hook)
+	return nil
+}
+
+func WithHook(hook Hook) EndpointOption {
+	return &withHookEndpointOption{
+		hook: hook,
+	}
+}
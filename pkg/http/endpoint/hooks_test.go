@@ -0,0 +1,70 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/httptest"
+)
+
+func Test_Endpoint_WithHook(t *testing.T) {
+	server := httptest.New(t)
+	requests := NewInMemoryCounter()
+	duration := NewInMemoryHistogram(nil)
+	exporter := NewInMemorySpanExporter()
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as/{paramA}",
+		server.Requester(),
+		WithRetry(policy),
+		WithHook(NewMetricsHook(requests, duration)),
+		WithHook(NewTraceHook(exporter)),
+	)
+	urlTemplate := server.BaseURL() + "/api/v1/as/{paramA}"
+
+	traceParent := ""
+	server.
+		Get("/api/v1/as/some_value").
+		Return(503, nil, http.Header{}).
+		DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			traceParent = req.Header.Get("traceparent")
+			return &http.Response{
+				StatusCode: 200,
+				Body:       http.NoBody,
+			}, nil
+		})
+
+	ctx := ContextWithTraceParent(
+		context.Background(),
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	)
+	_, err := end.Get(ctx,
+		WithParam("paramA", "some_value"),
+	)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	assert.Equal(t, float64(1), requests.Value(http.MethodGet, urlTemplate, "200"))
+	assert.Equal(t, uint64(1), duration.Snapshot(http.MethodGet, urlTemplate, "200").Count)
+
+	spans := exporter.Spans()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	assert.Equal(t, spans[0].TraceParent(), traceParent)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", spans[0].TraceID)
+	assert.Equal(t, "b7ad6b7169203331", spans[0].ParentSpanID)
+	assert.Equal(t, map[string]string{
+		"http.method":       http.MethodGet,
+		"http.url_template": urlTemplate,
+		"http.attempts":     "2",
+		"http.status_code":  "200",
+	}, spans[0].Attributes)
+}
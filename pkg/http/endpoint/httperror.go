@@ -0,0 +1,94 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+type Problem struct {
+	Type       string         `json:"type,omitempty"`
+	Title      string         `json:"title,omitempty"`
+	Status     int            `json:"status,omitempty"`
+	Detail     string         `json:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty"`
+	Extensions map[string]any `json:"-"`
+}
+
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	type problem Problem
+	content := problem{}
+	err := json.Unmarshal(data, &content)
+	if err != nil {
+		return err
+	}
+	extensions := map[string]any{}
+	err = json.Unmarshal(data, &extensions)
+	if err != nil {
+		return err
+	}
+	for _, key := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(extensions, key)
+	}
+	*p = Problem(content)
+	if len(extensions) > 0 {
+		p.Extensions = extensions
+	}
+	return nil
+}
+
+type HTTPError struct {
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+	Method      string
+	URLTemplate string
+	URL         string
+	Problem     *Problem
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("endpoint: response error - %d - %s", e.StatusCode, string(e.Body))
+}
+
+func NewHTTPError(res *http.Response, body []byte) *HTTPError {
+	httpErr := &HTTPError{
+		StatusCode: res.StatusCode,
+		Header:     res.Header,
+		Body:       body,
+	}
+	if res.Request != nil {
+		httpErr.Method = res.Request.Method
+		httpErr.URL = res.Request.URL.String()
+	}
+	mediaType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err == nil && mediaType == "application/problem+json" {
+		problem := &Problem{}
+		if json.Unmarshal(body, problem) == nil {
+			httpErr.Problem = problem
+		}
+	}
+	return httpErr
+}
+
+func AsHTTPError(err error) (*HTTPError, bool) {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr, true
+	}
+	return nil, false
+}
+
+func IsStatus(err error, statuses ...int) bool {
+	httpErr, ok := AsHTTPError(err)
+	return ok && slices.Contains(statuses, httpErr.StatusCode)
+}
+
+func IsProblemType(err error, problemType string) bool {
+	httpErr, ok := AsHTTPError(err)
+	return ok && httpErr.Problem != nil && httpErr.Problem.Type == problemType
+}
@@ -0,0 +1,64 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/http/httptest"
+)
+
+func Test_Endpoint_HTTPError(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as/{paramA}",
+		server.Requester(),
+		WithDefaultErrAnalyzer(),
+		WithNotFoundErrAnalyzer(),
+	)
+
+	server.
+		Get("/api/v1/as/some_value").
+		Return(
+			422,
+			[]byte(`{"type":"https://example.com/probs/out-of-credit","title":"You do not have enough credit.","status":422,"balance":30}`),
+			http.Header{
+				"Content-Type": []string{"application/problem+json"},
+			},
+		).
+		Return(
+			404,
+			[]byte(`Not Found`),
+			http.Header{},
+		)
+
+	_, err := end.Get(context.Background(),
+		WithParam("paramA", "some_value"),
+	)
+	assert.True(t, IsStatus(err, 422))
+	assert.True(t, IsProblemType(err, "https://example.com/probs/out-of-credit"))
+
+	httpErr, ok := AsHTTPError(err)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, http.MethodGet, httpErr.Method)
+	assert.Equal(t, server.BaseURL()+"/api/v1/as/{paramA}", httpErr.URLTemplate)
+	assert.Equal(t, server.BaseURL()+"/api/v1/as/some_value", httpErr.URL)
+	assert.Equal(t, &Problem{
+		Type:   "https://example.com/probs/out-of-credit",
+		Title:  "You do not have enough credit.",
+		Status: 422,
+		Extensions: map[string]any{
+			"balance": float64(30),
+		},
+	}, httpErr.Problem)
+
+	_, err = end.Get(context.Background(),
+		WithParam("paramA", "some_value"),
+	)
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+	assert.Equal(t, "endpoint: response error - 404 - Not Found", err.Error())
+}
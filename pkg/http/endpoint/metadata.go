@@ -0,0 +1,94 @@
+package endpoint
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+type contextHeadersKey struct{}
+
+// ContextWithHeaders attaches headers sent by every endpoint call made with
+// the context, values replace the ones already attached under the same key.
+func ContextWithHeaders(ctx context.Context, headers http.Header) context.Context {
+	merged := HeadersFromContext(ctx)
+	for key, values := range headers {
+		merged[http.CanonicalHeaderKey(key)] = append([]string{}, values...)
+	}
+	return context.WithValue(ctx, contextHeadersKey{}, merged)
+}
+
+func HeadersFromContext(ctx context.Context) http.Header {
+	headers, ok := ctx.Value(contextHeadersKey{}).(http.Header)
+	if !ok {
+		return http.Header{}
+	}
+	return headers.Clone()
+}
+
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return ContextWithHeaders(ctx, http.Header{
+		RequestIDHeader: []string{requestID},
+	})
+}
+
+func RequestIDFromContext(ctx context.Context) string {
+	headers, _ := ctx.Value(contextHeadersKey{}).(http.Header)
+	return headers.Get(RequestIDHeader)
+}
+
+func NewRequestID() string {
+	data := make([]byte, 16)
+	_, _ = rand.Read(data)
+	data[6] = (data[6] & 0x0f) | 0x40
+	data[8] = (data[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", data[0:4], data[4:6], data[6:8], data[8:10], data[10:])
+}
+
+func ensureRequestID(ctx context.Context) context.Context {
+	if RequestIDFromContext(ctx) != "" {
+		return ctx
+	}
+	return ContextWithRequestID(ctx, NewRequestID())
+}
+
+// mergeContextHeaders adds the context headers the request options did not set.
+func mergeContextHeaders(ctx context.Context, req *http.Request) {
+	headers, ok := ctx.Value(contextHeadersKey{}).(http.Header)
+	if !ok || len(headers) == 0 {
+		return
+	}
+	req.Header = req.Header.Clone()
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	for key, values := range headers {
+		if _, ok := req.Header[key]; !ok {
+			req.Header[key] = append([]string{}, values...)
+		}
+	}
+}
+
+// HeaderPropagationMiddleware moves the given headers, and always the request
+// id, from incoming server requests into their context so the endpoint calls
+// made while handling them forward the same values. A request id is generated
+// when the incoming request has none, and echoed back in the response.
+func HeaderPropagationMiddleware(headers ...string) func(http.Handler) http.Handler {
+	names := append([]string{RequestIDHeader}, headers...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			propagated := http.Header{}
+			for _, name := range names {
+				if values := r.Header.Values(name); len(values) > 0 {
+					propagated[http.CanonicalHeaderKey(name)] = values
+				}
+			}
+			ctx := ensureRequestID(ContextWithHeaders(r.Context(), propagated))
+			w.Header().Set(RequestIDHeader, RequestIDFromContext(ctx))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package endpoint
+
+import (
+	"context"
+	"io"
+	"net/http"
+	nethttptest "net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+func headersEndpoint(captured *[]http.Header, statuses ...int) Endpoint {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	return NewEndpoint(
+		"http://example.com",
+		"/items",
+		requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+			*captured = append(*captured, req.Header.Clone())
+			status := http.StatusOK
+			if len(*captured) <= len(statuses) {
+				status = statuses[len(*captured)-1]
+			}
+			return &http.Response{
+				StatusCode: status,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}),
+		WithRetry(policy),
+	)
+}
+
+func Test_Endpoint_ContextHeaders(t *testing.T) {
+	captured := []http.Header{}
+	end := headersEndpoint(&captured, http.StatusServiceUnavailable)
+
+	ctx := ContextWithHeaders(context.Background(), http.Header{
+		"X-Tenant-Id": []string{"tenant-a"},
+		"X-Source":    []string{"context"},
+	})
+	_, err := end.Get(ctx, WithHeaderParam("X-Source", "option"))
+	if !assertutil.Error(t, nil, err) || !assert.Len(t, captured, 2) {
+		return
+	}
+	requestID := captured[0].Get(RequestIDHeader)
+	assert.NotEmpty(t, requestID)
+	for _, header := range captured {
+		assert.Equal(t, requestID, header.Get(RequestIDHeader))
+		assert.Equal(t, "tenant-a", header.Get("X-Tenant-Id"))
+		assert.Equal(t, []string{"option"}, header.Values("X-Source"))
+	}
+
+	captured = captured[:0]
+	_, err = end.Get(ctx)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.NotEqual(t, requestID, captured[0].Get(RequestIDHeader))
+	assert.Empty(t, RequestIDFromContext(ctx))
+}
+
+func Test_HeaderPropagationMiddleware(t *testing.T) {
+	captured := []http.Header{}
+	end := headersEndpoint(&captured)
+	handler := HeaderPropagationMiddleware("X-Tenant-Id")(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			_, err := end.Get(r.Context())
+			assertutil.Error(t, nil, err)
+		},
+	))
+
+	req := nethttptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(RequestIDHeader, "incoming-id")
+	req.Header.Set("X-Tenant-Id", "tenant-a")
+	req.Header.Set("X-Other", "ignored")
+	recorder := nethttptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if !assert.Len(t, captured, 1) {
+		return
+	}
+	assert.Equal(t, "incoming-id", captured[0].Get(RequestIDHeader))
+	assert.Equal(t, "tenant-a", captured[0].Get("X-Tenant-Id"))
+	assert.Empty(t, captured[0].Get("X-Other"))
+	assert.Equal(t, "incoming-id", recorder.Header().Get(RequestIDHeader))
+
+	captured = captured[:0]
+	recorder = nethttptest.NewRecorder()
+	handler.ServeHTTP(recorder, nethttptest.NewRequest(http.MethodGet, "/", nil))
+	if !assert.Len(t, captured, 1) {
+		return
+	}
+	assert.NotEmpty(t, recorder.Header().Get(RequestIDHeader))
+	assert.Equal(t, recorder.Header().Get(RequestIDHeader), captured[0].Get(RequestIDHeader))
+}
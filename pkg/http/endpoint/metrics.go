@@ -0,0 +1,165 @@
+package endpoint
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var MetricLabels = []string{"method", "url_template", "status"}
+
+var DefaultDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type Counter interface {
+	Inc(labels ...string)
+}
+
+type CounterFunc func(labels ...string)
+
+func (fn CounterFunc) Inc(labels ...string) {
+	fn(labels...)
+}
+
+type Histogram interface {
+	Observe(value float64, labels ...string)
+}
+
+type HistogramFunc func(value float64, labels ...string)
+
+func (fn HistogramFunc) Observe(value float64, labels ...string) {
+	fn(value, labels...)
+}
+
+type metricsHook struct {
+	requests Counter
+	duration Histogram
+}
+
+func (h *metricsHook) Started(ctx context.Context, info CallInfo) context.Context {
+	return ctx
+}
+
+func (h *metricsHook) labels(info CallInfo) []string {
+	status := "error"
+	if info.Status != 0 {
+		status = strconv.Itoa(info.Status)
+	}
+	return []string{info.Method, info.URLTemplate, status}
+}
+
+func (h *metricsHook) observe(info CallInfo) {
+	labels := h.labels(info)
+	if h.requests != nil {
+		h.requests.Inc(labels...)
+	}
+	if h.duration != nil {
+		h.duration.Observe(info.Duration.Seconds(), labels...)
+	}
+}
+
+func (h *metricsHook) Finished(ctx context.Context, info CallInfo) {
+	h.observe(info)
+}
+
+func (h *metricsHook) Failed(ctx context.Context, info CallInfo) {
+	h.observe(info)
+}
+
+// NewMetricsHook reports every call using MetricLabels, with the duration in
+// seconds; a Prometheus vector fits through CounterFunc and HistogramFunc.
+func NewMetricsHook(requests Counter, duration Histogram) Hook {
+	return &metricsHook{
+		requests: requests,
+		duration: duration,
+	}
+}
+
+func metricKey(labels []string) string {
+	return strings.Join(labels, "\x00")
+}
+
+type InMemoryCounter struct {
+	lock   sync.Mutex
+	values map[string]float64
+}
+
+func NewInMemoryCounter() *InMemoryCounter {
+	return &InMemoryCounter{
+		values: map[string]float64{},
+	}
+}
+
+func (c *InMemoryCounter) Inc(labels ...string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.values[metricKey(labels)]++
+}
+
+func (c *InMemoryCounter) Value(labels ...string) float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.values[metricKey(labels)]
+}
+
+type HistogramSnapshot struct {
+	Count   uint64
+	Sum     float64
+	Buckets map[float64]uint64
+}
+
+type InMemoryHistogram struct {
+	lock    sync.Mutex
+	buckets []float64
+	values  map[string]*HistogramSnapshot
+}
+
+func NewInMemoryHistogram(buckets []float64) *InMemoryHistogram {
+	if buckets == nil {
+		buckets = DefaultDurationBuckets
+	}
+	sorted := append([]float64{}, buckets...)
+	sort.Float64s(sorted)
+	return &InMemoryHistogram{
+		buckets: sorted,
+		values:  map[string]*HistogramSnapshot{},
+	}
+}
+
+func (h *InMemoryHistogram) Observe(value float64, labels ...string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	key := metricKey(labels)
+	snapshot, ok := h.values[key]
+	if !ok {
+		snapshot = &HistogramSnapshot{
+			Buckets: map[float64]uint64{},
+		}
+		h.values[key] = snapshot
+	}
+	snapshot.Count++
+	snapshot.Sum += value
+	for _, bucket := range h.buckets {
+		if value <= bucket {
+			snapshot.Buckets[bucket]++
+		}
+	}
+}
+
+func (h *InMemoryHistogram) Snapshot(labels ...string) HistogramSnapshot {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	snapshot, ok := h.values[metricKey(labels)]
+	if !ok {
+		return HistogramSnapshot{
+			Buckets: map[float64]uint64{},
+		}
+	}
+	result := *snapshot
+	result.Buckets = make(map[float64]uint64, len(snapshot.Buckets))
+	for bucket, count := range snapshot.Buckets {
+		result.Buckets[bucket] = count
+	}
+	return result
+}
@@ -0,0 +1,217 @@
+package endpoint
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+type multipartPart struct {
+	fieldName   string
+	fileName    string
+	contentType string
+	value       string
+	content     io.Reader
+}
+
+type MultipartBuilder struct {
+	boundary string
+	parts    []multipartPart
+}
+
+func NewMultipartBuilder() *MultipartBuilder {
+	return &MultipartBuilder{
+		boundary: multipart.NewWriter(io.Discard).Boundary(),
+	}
+}
+
+func (b *MultipartBuilder) Field(name string, value string) *MultipartBuilder {
+	b.parts = append(b.parts, multipartPart{
+		fieldName: name,
+		value:     value,
+	})
+	return b
+}
+
+// File streams content when the body is sent. Content that does not implement
+// io.Seeker is also kept in memory while first sent, as retries and digest
+// auth may send the body again.
+func (b *MultipartBuilder) File(
+	fieldName string,
+	fileName string,
+	contentType string,
+	content io.Reader,
+) *MultipartBuilder {
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	b.parts = append(b.parts, multipartPart{
+		fieldName:   fieldName,
+		fileName:    fileName,
+		contentType: contentType,
+		content:     content,
+	})
+	return b
+}
+
+func (b *MultipartBuilder) ContentType() string {
+	return fmt.Sprintf("multipart/form-data; boundary=%s", b.boundary)
+}
+
+// write encodes the parts, reading each file from contents at its index.
+func (b *MultipartBuilder) write(w io.Writer, contents []io.Reader) error {
+	writer := multipart.NewWriter(w)
+	err := writer.SetBoundary(b.boundary)
+	if err != nil {
+		return errors.Wrap(err, "failed to set multipart boundary")
+	}
+	for idx, part := range b.parts {
+		if part.content == nil {
+			err = writer.WriteField(part.fieldName, part.value)
+			if err != nil {
+				return errors.Wrap(err, "failed to write multipart field")
+			}
+			continue
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(
+			`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(part.fieldName),
+			quoteEscaper.Replace(part.fileName),
+		))
+		header.Set("Content-Type", part.contentType)
+		partWriter, err := writer.CreatePart(header)
+		if err != nil {
+			return errors.Wrap(err, "failed to create multipart file")
+		}
+		_, err = io.Copy(partWriter, contents[idx])
+		if err != nil {
+			return errors.Wrap(err, "failed to write multipart file")
+		}
+	}
+	return errors.Wrap(writer.Close(), "failed to close multipart writer")
+}
+
+// multipartBody encodes the parts through a pipe while it is read, and only
+// supports seeking back to the start, which restarts the encoding. Files that
+// can not seek are kept in memory while the body is first read, so it can be
+// sent again once it was read to the end.
+type multipartBody struct {
+	builder  *MultipartBuilder
+	reader   *io.PipeReader
+	done     chan struct{}
+	started  bool
+	buffers  []*bytes.Buffer
+	buffered atomic.Bool
+}
+
+func (b *multipartBody) contents() []io.Reader {
+	if b.buffers == nil {
+		b.buffers = make([]*bytes.Buffer, len(b.builder.parts))
+	}
+	contents := make([]io.Reader, len(b.builder.parts))
+	for idx, part := range b.builder.parts {
+		switch {
+		case part.content == nil:
+		case isSeeker(part.content):
+			contents[idx] = part.content
+		case b.buffered.Load():
+			contents[idx] = bytes.NewReader(b.buffers[idx].Bytes())
+		default:
+			b.buffers[idx] = &bytes.Buffer{}
+			contents[idx] = io.TeeReader(part.content, b.buffers[idx])
+		}
+	}
+	return contents
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	if b.reader == nil {
+		pipeReader, pipeWriter := io.Pipe()
+		done := make(chan struct{})
+		contents := b.contents()
+		b.reader = pipeReader
+		b.done = done
+		b.started = true
+		go func() {
+			defer close(done)
+			err := b.builder.write(pipeWriter, contents)
+			if err == nil {
+				b.buffered.Store(true)
+			}
+			_ = pipeWriter.CloseWithError(err)
+		}()
+	}
+	return b.reader.Read(p)
+}
+
+// Close stops the encoding when the body is not read to the end, as when the
+// server rejects the upload early.
+func (b *multipartBody) Close() error {
+	if b.reader != nil {
+		_ = b.reader.CloseWithError(io.ErrClosedPipe)
+		<-b.done
+		b.reader = nil
+	}
+	return nil
+}
+
+// replayable reports whether the body can be sent again, which for files that
+// can not seek is only once they were read to the end.
+func (b *multipartBody) replayable() bool {
+	if !b.started || b.buffered.Load() {
+		return true
+	}
+	for _, part := range b.builder.parts {
+		if part.content != nil && !isSeeker(part.content) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *multipartBody) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekStart {
+		return 0, errors.New("endpoint: multipart body only supports seeking to start")
+	}
+	_ = b.Close()
+	for _, part := range b.builder.parts {
+		if part.content == nil {
+			continue
+		}
+		seeker, ok := part.content.(io.Seeker)
+		if !ok {
+			if b.started && !b.buffered.Load() {
+				return 0, errors.Wrapf(ErrUnreadableBody, "multipart file %s", part.fileName)
+			}
+			continue
+		}
+		_, err := seeker.Seek(0, io.SeekStart)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to reset multipart file %s", part.fileName)
+		}
+	}
+	return 0, nil
+}
+
+func isSeeker(content io.Reader) bool {
+	_, ok := content.(io.Seeker)
+	return ok
+}
+
+func WithMultipartBody(builder *MultipartBuilder) EndpointOption {
+	return &withRawBodyEndpointOption{
+		contentType: builder.ContentType(),
+		body: &multipartBody{
+			builder: builder,
+		},
+	}
+}
@@ -0,0 +1,269 @@
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/httptest"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+func Test_Endpoint_WithMultipartBody(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/uploads",
+		server.Requester(),
+	)
+
+	type upload struct {
+		Name        string
+		FileName    string
+		ContentType string
+		Content     string
+	}
+
+	builder := NewMultipartBuilder().
+		Field("name", "report").
+		File("export", "export.csv", "text/csv", strings.NewReader("id,name"))
+
+	expectedBody, err := io.ReadAll(&multipartBody{builder: builder})
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	server.
+		Body(expectedBody).
+		Post("/api/v1/uploads").
+		DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			err := req.ParseMultipartForm(1024)
+			if err != nil {
+				return nil, err
+			}
+			header := req.MultipartForm.File["export"][0]
+			file, err := header.Open()
+			if err != nil {
+				return nil, err
+			}
+			content, err := io.ReadAll(file)
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: 200,
+				Body: io.NopCloser(strings.NewReader(
+					`{"Name":"` + req.FormValue("name") +
+						`","FileName":"` + header.Filename +
+						`","ContentType":"` + header.Header.Get("Content-Type") +
+						`","Content":"` + string(content) + `"}`,
+				)),
+				Header: http.Header{
+					"Content-Type": []string{"application/json"},
+				},
+			}, nil
+		})
+
+	result, _, err := PostAs[upload](context.Background(), end,
+		WithMultipartBody(builder),
+	)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, upload{
+		Name:        "report",
+		FileName:    "export.csv",
+		ContentType: "text/csv",
+		Content:     "id,name",
+	}, result)
+}
+
+func Test_multipartBody_Seek(t *testing.T) {
+	body := &multipartBody{
+		builder: NewMultipartBuilder().
+			File("file", "a.txt", "", bytes.NewReader([]byte("content"))),
+	}
+
+	first, err := io.ReadAll(io.LimitReader(body, 10))
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	_, err = body.Seek(0, io.SeekStart)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	second, err := io.ReadAll(body)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.True(t, bytes.HasPrefix(second, first))
+	assert.Contains(t, string(second), "content")
+
+	body = &multipartBody{
+		builder: NewMultipartBuilder().
+			File("file", "a.txt", "", io.LimitReader(strings.NewReader("content"), 7)),
+	}
+	first, err = io.ReadAll(body)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	_, err = body.Seek(0, io.SeekStart)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	second, err = io.ReadAll(body)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, first, second)
+
+	// partially read files can not be sent again
+	body = &multipartBody{
+		builder: NewMultipartBuilder().
+			File("file", "a.txt", "", io.LimitReader(strings.NewReader("content"), 7)),
+	}
+	_, err = io.ReadAll(io.LimitReader(body, 10))
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.False(t, body.replayable())
+	_, err = body.Seek(0, io.SeekStart)
+	assert.ErrorIs(t, err, ErrUnreadableBody)
+}
+
+func Test_multipartBody_Close(t *testing.T) {
+	body := &multipartBody{
+		builder: NewMultipartBuilder().
+			File("file", "a.txt", "", bytes.NewReader(make([]byte, 1<<20))),
+	}
+	_, err := io.ReadAll(io.LimitReader(body, 10))
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	done := body.done
+
+	// the transport closes the body it stops reading
+	err = (&rewindBody{seeker: body, rewound: true}).Close()
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("multipart encoding not stopped")
+	}
+}
+
+func Test_Endpoint_WithMultipartBody_Unseekable(t *testing.T) {
+	commands := []string{}
+	var received []byte
+	end := NewEndpoint(
+		"http://example.com",
+		"/api/v1/uploads",
+		requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+			var err error
+			received, err = io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}),
+		WithCurlCommand(nil, func(command string) {
+			commands = append(commands, command)
+		}),
+	)
+
+	builder := NewMultipartBuilder().
+		File("file", "a.txt", "", io.LimitReader(strings.NewReader("content"), 7))
+	_, err := end.Post(context.Background(), WithMultipartBody(builder))
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Len(t, commands, 1)
+	assert.Contains(t, string(received), "content")
+}
+
+func Test_Endpoint_WithMultipartBody_UnseekableResend(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+
+	tests := []struct {
+		name    string
+		options []EndpointOption
+		status  int
+		partial bool
+		calls   int
+	}{
+		{
+			name:    "should resend the buffered body after a digest challenge",
+			options: []EndpointOption{WithDigestAuth("user", "pass")},
+			status:  http.StatusUnauthorized,
+			calls:   2,
+		},
+		{
+			name:    "should return the response when a partial body can not be retried",
+			options: []EndpointOption{WithRetry(policy), WithNotFoundErrAnalyzer()},
+			status:  http.StatusServiceUnavailable,
+			partial: true,
+			calls:   1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies := [][]byte{}
+			end := NewEndpoint(
+				"http://example.com",
+				"/api/v1/uploads",
+				requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+					var reader io.Reader = req.Body
+					if tt.partial {
+						// the server answers before the upload is over
+						reader = io.LimitReader(req.Body, 5)
+					}
+					body, err := io.ReadAll(reader)
+					if err != nil {
+						return nil, err
+					}
+					_ = req.Body.Close()
+					bodies = append(bodies, body)
+
+					status := tt.status
+					if req.Header.Get("Authorization") != "" {
+						status = http.StatusOK
+					}
+					return &http.Response{
+						StatusCode: status,
+						Header: http.Header{
+							"Www-Authenticate": []string{`Digest realm="test", nonce="abc", qop="auth"`},
+						},
+						Body: io.NopCloser(strings.NewReader("")),
+					}, nil
+				}),
+				tt.options...,
+			)
+
+			builder := NewMultipartBuilder().
+				File("file", "a.txt", "", io.LimitReader(strings.NewReader("content"), 7))
+			res, err := end.Put(context.Background(), WithMultipartBody(builder))
+			if !assertutil.Error(t, nil, err) || !assert.Len(t, bodies, tt.calls) {
+				return
+			}
+			if tt.calls > 1 {
+				assert.Equal(t, http.StatusOK, res.Status())
+				assert.Equal(t, bodies[0], bodies[1])
+				assert.Contains(t, string(bodies[1]), "content")
+			} else {
+				assert.Equal(t, tt.status, res.Status())
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package endpoint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+const (
+	oauth2ExpirySkew     = 30 * time.Second
+	oauth2DefaultTimeout = 30 * time.Second
+)
+
+type oauth2Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	expiry       time.Time
+}
+
+func (t *oauth2Token) valid(now time.Time) bool {
+	return t != nil && (t.expiry.IsZero() || now.Before(t.expiry))
+}
+
+func (t *oauth2Token) authorization() string {
+	tokenType := t.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+	return fmt.Sprintf("%s %s", tokenType, t.AccessToken)
+}
+
+type oauth2Flight struct {
+	done  chan struct{}
+	token *oauth2Token
+	err   error
+}
+
+type oauth2TokenSource struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	grantType    string
+	refreshToken string
+	timeout      time.Duration
+	now          func() time.Time
+
+	lock   sync.Mutex
+	token  *oauth2Token
+	flight *oauth2Flight
+}
+
+func (s *oauth2TokenSource) get(
+	ctx context.Context,
+	requester requester.Requester,
+) (*oauth2Token, error) {
+	s.lock.Lock()
+	if s.token.valid(s.now()) {
+		token := s.token
+		s.lock.Unlock()
+		return token, nil
+	}
+	flight := s.flight
+	if flight == nil {
+		flight = &oauth2Flight{
+			done: make(chan struct{}),
+		}
+		s.flight = flight
+		// the refresh is shared by every waiter, so it must outlive the caller that
+		// started it, the timeout keeps a hanging token server from holding it forever
+		go s.refresh(context.WithoutCancel(ctx), requester, flight)
+	}
+	s.lock.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return nil, errors.Wrap(ctx.Err(), "failed to wait oauth2 token")
+	case <-flight.done:
+		return flight.token, flight.err
+	}
+}
+
+func (s *oauth2TokenSource) refresh(
+	ctx context.Context,
+	requester requester.Requester,
+	flight *oauth2Flight,
+) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	token, err := s.fetch(ctx, requester)
+
+	s.lock.Lock()
+	if err == nil {
+		s.token = token
+		if token.RefreshToken != "" && s.grantType == "refresh_token" {
+			s.refreshToken = token.RefreshToken
+		}
+	}
+	s.flight = nil
+	s.lock.Unlock()
+
+	flight.token = token
+	flight.err = err
+	close(flight.done)
+}
+
+func (s *oauth2TokenSource) invalidate(token *oauth2Token) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.token == token {
+		s.token = nil
+	}
+}
+
+func (s *oauth2TokenSource) fetch(
+	ctx context.Context,
+	requester requester.Requester,
+) (*oauth2Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", s.grantType)
+	if len(s.scopes) > 0 {
+		form.Set("scope", strings.Join(s.scopes, " "))
+	}
+	if s.grantType == "refresh_token" {
+		s.lock.Lock()
+		form.Set("refresh_token", s.refreshToken)
+		s.lock.Unlock()
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		s.tokenURL,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create oauth2 token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+
+	issuedAt := s.now()
+	res, err := requester.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to execute oauth2 token request")
+	}
+	defer res.Body.Close()
+
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read oauth2 token response")
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("endpoint: oauth2 token error - %d - %s", res.StatusCode, string(content))
+	}
+
+	token := &oauth2Token{}
+	err = json.Unmarshal(content, token)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal oauth2 token")
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("endpoint: oauth2 token response without access token")
+	}
+	if token.ExpiresIn > 0 {
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+		token.expiry = issuedAt.Add(lifetime - min(oauth2ExpirySkew, lifetime/2))
+	}
+	return token, nil
+}
+
+func (s *oauth2TokenSource) authFn(
+	ctx context.Context,
+	requester requester.Requester,
+	request *http.Request,
+) (*http.Response, error) {
+	token, err := s.get(ctx, requester)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to recover oauth2 token")
+	}
+	request.Header.Set("Authorization", token.authorization())
+
+	res, err := requester.Do(request)
+	if err != nil {
+		return res, err
+	}
+	if res.StatusCode != http.StatusUnauthorized {
+		return res, nil
+	}
+	if request.GetBody == nil && request.Body != nil && request.Body != http.NoBody {
+		return res, nil
+	}
+
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+
+	s.invalidate(token)
+	token, err = s.get(ctx, requester)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to refresh oauth2 token")
+	}
+	if request.GetBody != nil {
+		request.Body, err = request.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to reset request body")
+		}
+	}
+	request.Header.Set("Authorization", token.authorization())
+	return requester.Do(request)
+}
+
+type OAuth2Option func(source *oauth2TokenSource)
+
+// WithOAuth2Timeout limits each token request, 30 seconds by default.
+func WithOAuth2Timeout(timeout time.Duration) OAuth2Option {
+	return func(source *oauth2TokenSource) {
+		source.timeout = timeout
+	}
+}
+
+func newOAuth2EndpointOption(
+	source *oauth2TokenSource,
+	options []OAuth2Option,
+) EndpointOption {
+	source.timeout = oauth2DefaultTimeout
+	source.now = time.Now
+	for _, option := range options {
+		option(source)
+	}
+	return &withOAuth2EndpointOption{
+		source: source,
+	}
+}
+
+type withOAuth2EndpointOption struct {
+	source *oauth2TokenSource
+}
+
+func (o *withOAuth2EndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	opts.authFn = o.source.authFn
+	return nil
+}
+
+// WithOAuth2ClientCredentials caches tokens inside the returned option, so it
+// should be created once and shared, usually as a NewEndpoint base option.
+func WithOAuth2ClientCredentials(
+	tokenURL string,
+	clientID string,
+	secret string,
+	scopes []string,
+	options ...OAuth2Option,
+) EndpointOption {
+	return newOAuth2EndpointOption(&oauth2TokenSource{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: secret,
+		scopes:       scopes,
+		grantType:    "client_credentials",
+	}, options)
+}
+
+func WithOAuth2RefreshToken(
+	tokenURL string,
+	clientID string,
+	secret string,
+	refreshToken string,
+	scopes []string,
+	options ...OAuth2Option,
+) EndpointOption {
+	return newOAuth2EndpointOption(&oauth2TokenSource{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: secret,
+		scopes:       scopes,
+		grantType:    "refresh_token",
+		refreshToken: refreshToken,
+	}, options)
+}
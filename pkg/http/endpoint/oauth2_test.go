@@ -0,0 +1,178 @@
+package endpoint
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/httptest"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+func Test_Endpoint_WithOAuth2ClientCredentials(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as",
+		server.Requester(),
+		WithOAuth2ClientCredentials(
+			server.BaseURL()+"/oauth/token",
+			"client",
+			"secret",
+			[]string{"read", "write"},
+		),
+	)
+
+	server.
+		Header(http.Header{
+			"Authorization": []string{"Basic Y2xpZW50OnNlY3JldA=="},
+		}).
+		Body([]byte(`grant_type=client_credentials&scope=read+write`)).
+		Post("/oauth/token").
+		ReturnJSON(
+			200,
+			map[string]any{
+				"access_token": "first",
+				"token_type":   "bearer",
+				"expires_in":   3600,
+			},
+			http.Header{},
+		).
+		ReturnJSON(
+			200,
+			map[string]any{
+				"access_token": "second",
+				"token_type":   "bearer",
+				"expires_in":   3600,
+			},
+			http.Header{},
+		)
+
+	server.
+		Header(http.Header{
+			"Authorization": []string{"Bearer first"},
+		}).
+		Get("/api/v1/as").
+		ReturnJSON(
+			200,
+			someType{ID: 1},
+			http.Header{},
+		).
+		Return(
+			401,
+			[]byte(`expired`),
+			http.Header{},
+		)
+
+	server.
+		Header(http.Header{
+			"Authorization": []string{"Bearer second"},
+		}).
+		Get("/api/v1/as").
+		ReturnJSON(
+			200,
+			someType{ID: 2},
+			http.Header{},
+		)
+
+	result, _, err := GetAs[someType](context.Background(), end)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, someType{ID: 1}, result)
+
+	result, _, err = GetAs[someType](context.Background(), end)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, someType{ID: 2}, result)
+}
+
+// tokenRequester answers token requests with the given handler and any other
+// request with an empty 200.
+func tokenRequester(token func(req *http.Request) (*http.Response, error)) requester.Requester {
+	return requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/oauth/token" {
+			return token(req)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+}
+
+func tokenResponse() *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(`{"access_token":"token","expires_in":3600}`)),
+	}
+}
+
+func Test_Endpoint_WithOAuth2ClientCredentials_SharedRefresh(t *testing.T) {
+	var tokenCalls atomic.Int32
+	end := NewEndpoint(
+		"http://example.com",
+		"/api/v1/as",
+		tokenRequester(func(req *http.Request) (*http.Response, error) {
+			tokenCalls.Add(1)
+			time.Sleep(20 * time.Millisecond)
+			return tokenResponse(), nil
+		}),
+		WithOAuth2ClientCredentials("http://example.com/oauth/token", "client", "secret", nil),
+	)
+
+	wg := sync.WaitGroup{}
+	for idx := 0; idx < 10; idx++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := end.Get(context.Background())
+			if assertutil.Error(t, nil, err) {
+				_ = res.Close()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), tokenCalls.Load())
+}
+
+func Test_Endpoint_WithOAuth2ClientCredentials_Timeout(t *testing.T) {
+	var tokenCalls atomic.Int32
+	end := NewEndpoint(
+		"http://example.com",
+		"/api/v1/as",
+		tokenRequester(func(req *http.Request) (*http.Response, error) {
+			if tokenCalls.Add(1) == 1 {
+				<-req.Context().Done()
+				return nil, req.Context().Err()
+			}
+			return tokenResponse(), nil
+		}),
+		WithOAuth2ClientCredentials(
+			"http://example.com/oauth/token",
+			"client",
+			"secret",
+			nil,
+			WithOAuth2Timeout(10*time.Millisecond),
+		),
+	)
+
+	_, err := end.Get(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// the timed out refresh does not stay in flight for the next callers
+	res, err := end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	_ = res.Close()
+	assert.Equal(t, int32(2), tokenCalls.Load())
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -17,6 +18,18 @@ type endpointOptions struct {
 	headers      http.Header
 	authFn       AuthFn
 	errAnalyzers []ErrAnalyzerFn
+	retryPolicy  *RetryPolicy
+	statusErrors map[int]error
+	codecs       map[string]Codec
+	pendingBody  *pendingBody
+	bodyEncoding string
+	rawURL       string
+	hooks        []Hook
+	curlCommand  *curlCommand
+
+	timeout          time.Duration
+	firstByteTimeout time.Duration
+	idleTimeout      time.Duration
 }
 
 type EndpointOption interface {
@@ -49,6 +62,16 @@ func newEndpointOptions(
 		}
 	}
 
+	err := opts.encodePendingBody()
+	if err != nil {
+		return nil, err
+	}
+
+	err = opts.compressBody()
+	if err != nil {
+		return nil, err
+	}
+
 	if len(opts.errAnalyzers) == 0 {
 		opts.errAnalyzers = []ErrAnalyzerFn{defaultErrAnalyzer}
 	}
@@ -59,11 +82,16 @@ func newEndpointOptions(
 func (e *endpoint) parseOptionsToRequest(
 	ctx context.Context,
 	method string,
+	urlTemplate string,
 	opts *endpointOptions,
 ) (*http.Request, error) {
-	parsedURL, err := opts.replaceURLParams(e.URL)
-	if err != nil {
-		return nil, err
+	parsedURL := opts.rawURL
+	if parsedURL == "" {
+		var err error
+		parsedURL, err = opts.replaceURLParams(urlTemplate)
+		if err != nil {
+			return nil, err
+		}
 	}
 	body := opts.body
 	if body == nil {
@@ -80,12 +108,64 @@ func (e *endpoint) parseOptionsToRequest(
 		return nil, errors.Wrap(err, "failed to create request")
 	}
 
+	if seeker, ok := body.(io.ReadSeeker); ok && req.GetBody == nil {
+		req.Body = &rewindBody{seeker: seeker}
+		req.GetBody = func() (io.ReadCloser, error) {
+			return &rewindBody{seeker: seeker}, nil
+		}
+	}
+
 	if opts.headers != nil {
 		req.Header = opts.headers
 	}
 	if opts.query != nil {
-		req.URL.RawQuery = opts.query.Encode()
+		if opts.rawURL != "" {
+			query := req.URL.Query()
+			for key, values := range opts.query {
+				if _, ok := query[key]; !ok {
+					query[key] = values
+				}
+			}
+			req.URL.RawQuery = query.Encode()
+		} else {
+			req.URL.RawQuery = opts.query.Encode()
+		}
 	}
 
 	return req, nil
 }
+
+// rewindBody seeks its reader back to the start on the first read, so the
+// request body and every GetBody copy can be consumed one after the other.
+type rewindBody struct {
+	seeker  io.ReadSeeker
+	rewound bool
+}
+
+func (b *rewindBody) Read(p []byte) (int, error) {
+	if !b.rewound {
+		b.rewound = true
+		_, err := b.seeker.Seek(0, io.SeekStart)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to reset body reader")
+		}
+	}
+	return b.seeker.Read(p)
+}
+
+func (b *rewindBody) Close() error {
+	// only bodies built here are closed, readers given by the caller stay
+	// open for the next attempts and are theirs to close
+	switch body := b.seeker.(type) {
+	case *multipartBody:
+		return body.Close()
+	case *compressedBody:
+		return body.Close()
+	}
+	return nil
+}
+
+func isReplayable(body io.Reader) bool {
+	replayable, ok := body.(interface{ replayable() bool })
+	return !ok || replayable.replayable()
+}
@@ -0,0 +1,301 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var ErrForeignNextLink = errors.New("endpoint: next link to another origin")
+
+type PageStrategy[T any] interface {
+	First() []EndpointOption
+	// Next decodes the items of the page at the given zero based index and
+	// returns the options for the following page, or nil when it is the last one.
+	Next(res Response, page int) (items []T, next []EndpointOption, err error)
+}
+
+type pageNumberStrategy[T any] struct {
+	pageParam string
+	firstPage int
+}
+
+func (s *pageNumberStrategy[T]) First() []EndpointOption {
+	return []EndpointOption{
+		WithQueryParam(s.pageParam, s.firstPage),
+	}
+}
+
+func (s *pageNumberStrategy[T]) Next(res Response, page int) ([]T, []EndpointOption, error) {
+	items := []T{}
+	err := res.Unmarshal(&items)
+	if err != nil {
+		return nil, nil, err
+	}
+	return items, []EndpointOption{
+		WithQueryParam(s.pageParam, s.firstPage+page+1),
+	}, nil
+}
+
+func PageNumberStrategy[T any](pageParam string, firstPage int) PageStrategy[T] {
+	return &pageNumberStrategy[T]{
+		pageParam: pageParam,
+		firstPage: firstPage,
+	}
+}
+
+type offsetLimitStrategy[T any] struct {
+	offsetParam string
+	limitParam  string
+	limit       int
+}
+
+func (s *offsetLimitStrategy[T]) options(offset int) []EndpointOption {
+	return []EndpointOption{
+		WithQueryParam(s.offsetParam, offset),
+		WithQueryParam(s.limitParam, s.limit),
+	}
+}
+
+func (s *offsetLimitStrategy[T]) First() []EndpointOption {
+	return s.options(0)
+}
+
+func (s *offsetLimitStrategy[T]) Next(res Response, page int) ([]T, []EndpointOption, error) {
+	items := []T{}
+	err := res.Unmarshal(&items)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(items) < s.limit {
+		return items, nil, nil
+	}
+	return items, s.options((page + 1) * s.limit), nil
+}
+
+func OffsetLimitStrategy[T any](offsetParam string, limitParam string, limit int) PageStrategy[T] {
+	return &offsetLimitStrategy[T]{
+		offsetParam: offsetParam,
+		limitParam:  limitParam,
+		limit:       limit,
+	}
+}
+
+type cursorStrategy[T any, P any] struct {
+	cursorParam string
+	extract     func(page P) ([]T, string)
+}
+
+func (s *cursorStrategy[T, P]) First() []EndpointOption {
+	return nil
+}
+
+func (s *cursorStrategy[T, P]) Next(res Response, page int) ([]T, []EndpointOption, error) {
+	var content P
+	err := res.Unmarshal(&content)
+	if err != nil {
+		return nil, nil, err
+	}
+	items, cursor := s.extract(content)
+	if cursor == "" {
+		return items, nil, nil
+	}
+	return items, []EndpointOption{
+		WithQueryParam(s.cursorParam, cursor),
+	}, nil
+}
+
+func CursorStrategy[T any, P any](cursorParam string, extract func(page P) ([]T, string)) PageStrategy[T] {
+	return &cursorStrategy[T, P]{
+		cursorParam: cursorParam,
+		extract:     extract,
+	}
+}
+
+var linkRelRegex = regexp.MustCompile(`(?i)rel="?([^";]+)"?`)
+
+func parseLinkHeader(values []string, rel string) string {
+	for _, value := range values {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			target := strings.TrimSpace(parts[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range parts[1:] {
+				match := linkRelRegex.FindStringSubmatch(param)
+				if match == nil {
+					continue
+				}
+				for _, linkRel := range strings.Fields(match[1]) {
+					if strings.EqualFold(linkRel, rel) {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
+
+type withRawURLEndpointOption struct {
+	rawURL string
+}
+
+func (o *withRawURLEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	opts.rawURL = o.rawURL
+	return nil
+}
+
+type linkHeaderStrategy[T any] struct{}
+
+func (s *linkHeaderStrategy[T]) First() []EndpointOption {
+	return nil
+}
+
+func (s *linkHeaderStrategy[T]) Next(res Response, page int) ([]T, []EndpointOption, error) {
+	items := []T{}
+	err := res.Unmarshal(&items)
+	if err != nil {
+		return nil, nil, err
+	}
+	link := parseLinkHeader(res.Headers().Values("Link"), "next")
+	if link == "" {
+		return items, nil, nil
+	}
+	nextURL, err := url.Parse(link)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to parse next link")
+	}
+	if r, ok := res.(*response); ok && r.Response.Request != nil {
+		current := r.Response.Request.URL
+		nextURL = current.ResolveReference(nextURL)
+		// the endpoint auth and headers must not be sent to other origins
+		if !strings.EqualFold(nextURL.Scheme, current.Scheme) ||
+			!strings.EqualFold(nextURL.Host, current.Host) {
+			return nil, nil, errors.Wrapf(ErrForeignNextLink, "%s", nextURL.Redacted())
+		}
+	} else if nextURL.IsAbs() {
+		return nil, nil, errors.Wrapf(ErrForeignNextLink, "%s", nextURL.Redacted())
+	}
+	return items, []EndpointOption{
+		&withRawURLEndpointOption{
+			rawURL: nextURL.String(),
+		},
+	}, nil
+}
+
+func LinkHeaderStrategy[T any]() PageStrategy[T] {
+	return &linkHeaderStrategy[T]{}
+}
+
+type Pager[T any] struct {
+	ctx      context.Context
+	end      Endpoint
+	strategy PageStrategy[T]
+	options  []EndpointOption
+	maxPages int
+	maxItems int
+	next     []EndpointOption
+	done     bool
+	pages    int
+	items    int
+	buffer   []T
+	current  T
+	err      error
+}
+
+func Paginate[T any](
+	ctx context.Context,
+	end Endpoint,
+	strategy PageStrategy[T],
+	options ...EndpointOption,
+) *Pager[T] {
+	return &Pager[T]{
+		ctx:      ctx,
+		end:      end,
+		strategy: strategy,
+		options:  options,
+		next:     strategy.First(),
+	}
+}
+
+func (p *Pager[T]) MaxPages(maxPages int) *Pager[T] {
+	p.maxPages = maxPages
+	return p
+}
+
+func (p *Pager[T]) MaxItems(maxItems int) *Pager[T] {
+	p.maxItems = maxItems
+	return p
+}
+
+func (p *Pager[T]) fetch() error {
+	options := make([]EndpointOption, 0, len(p.options)+len(p.next))
+	options = append(options, p.options...)
+	options = append(options, p.next...)
+
+	res, err := p.end.Get(p.ctx, options...)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	if res.Status() < http.StatusOK || res.Status() >= http.StatusMultipleChoices {
+		return errors.Errorf("endpoint: unexpected page status - %d", res.Status())
+	}
+
+	items, next, err := p.strategy.Next(res, p.pages)
+	if err != nil {
+		return err
+	}
+	p.pages++
+	p.buffer = items
+	p.next = next
+	if len(items) == 0 || next == nil || (p.maxPages > 0 && p.pages >= p.maxPages) {
+		p.done = true
+	}
+	return nil
+}
+
+func (p *Pager[T]) Next() bool {
+	if p.err != nil || (p.maxItems > 0 && p.items >= p.maxItems) {
+		return false
+	}
+	for len(p.buffer) == 0 {
+		if p.done {
+			return false
+		}
+		p.err = p.fetch()
+		if p.err != nil {
+			return false
+		}
+	}
+	p.current = p.buffer[0]
+	p.buffer = p.buffer[1:]
+	p.items++
+	return true
+}
+
+func (p *Pager[T]) Item() T {
+	return p.current
+}
+
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+func (p *Pager[T]) All() ([]T, error) {
+	result := []T{}
+	for p.Next() {
+		result = append(result, p.Item())
+	}
+	return result, p.Err()
+}
@@ -0,0 +1,141 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/httptest"
+)
+
+func Test_Paginate_PageNumber(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as",
+		server.Requester(),
+	)
+
+	pages := [][]someType{
+		{{ID: 1}, {ID: 2}},
+		{{ID: 3}},
+		{},
+	}
+	for idx, page := range pages {
+		server.
+			Query(url.Values{
+				"page": []string{fmt.Sprint(idx + 1)},
+			}).
+			Get("/api/v1/as").
+			ReturnJSON(200, page, http.Header{})
+	}
+
+	result, err := Paginate(context.Background(), end,
+		PageNumberStrategy[someType]("page", 1),
+	).All()
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, []someType{{ID: 1}, {ID: 2}, {ID: 3}}, result)
+}
+
+func Test_Paginate_Cursor(t *testing.T) {
+	type cursorPage struct {
+		Items      []someType `json:"items"`
+		NextCursor string     `json:"next_cursor"`
+	}
+
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as",
+		server.Requester(),
+	)
+
+	server.
+		Query(url.Values{
+			"size": []string{"2"},
+		}).
+		Get("/api/v1/as").
+		ReturnJSON(200, cursorPage{
+			Items:      []someType{{ID: 1}, {ID: 2}},
+			NextCursor: "abc",
+		}, http.Header{})
+	server.
+		Query(url.Values{
+			"size":   []string{"2"},
+			"cursor": []string{"abc"},
+		}).
+		Get("/api/v1/as").
+		ReturnJSON(200, cursorPage{
+			Items: []someType{{ID: 3}},
+		}, http.Header{})
+
+	result, err := Paginate(context.Background(), end,
+		CursorStrategy("cursor", func(page cursorPage) ([]someType, string) {
+			return page.Items, page.NextCursor
+		}),
+		WithQueryParam("size", 2),
+	).All()
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, []someType{{ID: 1}, {ID: 2}, {ID: 3}}, result)
+}
+
+func Test_Paginate_LinkHeader_MaxItems(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as",
+		server.Requester(),
+	)
+
+	server.
+		Get("/api/v1/as").
+		ReturnJSON(200, []someType{{ID: 1}, {ID: 2}}, http.Header{
+			"Link": []string{`</api/v1/as?page=2>; rel="next", </api/v1/as?page=9>; rel="last"`},
+		})
+	server.
+		Query(url.Values{
+			"page": []string{"2"},
+		}).
+		Get("/api/v1/as").
+		ReturnJSON(200, []someType{{ID: 3}, {ID: 4}}, http.Header{
+			"Link": []string{`</api/v1/as?page=3>; rel="next"`},
+		})
+
+	result, err := Paginate(context.Background(), end,
+		LinkHeaderStrategy[someType](),
+	).MaxItems(3).All()
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, []someType{{ID: 1}, {ID: 2}, {ID: 3}}, result)
+}
+
+func Test_Paginate_LinkHeader_ForeignOrigin(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as",
+		server.Requester(),
+		WithBearerTokenAuth("secret"),
+	)
+
+	server.
+		Get("/api/v1/as").
+		ReturnJSON(200, []someType{{ID: 1}}, http.Header{
+			"Link": []string{`<http://attacker.example/collect>; rel="next"`},
+		})
+
+	result, err := Paginate(context.Background(), end,
+		LinkHeaderStrategy[someType](),
+	).All()
+	assert.ErrorIs(t, err, ErrForeignNextLink)
+	assert.Equal(t, []someType{}, result)
+}
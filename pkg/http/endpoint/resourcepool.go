@@ -3,6 +3,7 @@ package endpoint
 import (
 	"compress/gzip"
 	"context"
+	"io"
 
 	"github.com/jackc/puddle/v2"
 	"github.com/vitorsss/go-helpers/pkg/logs"
@@ -26,3 +27,17 @@ func init() {
 		panic(err)
 	}
 }
+
+func acquireGzipReader(r io.Reader) (*gzip.Reader, func(), error) {
+	resource, err := gzipReaderPool.TryAcquire(context.Background())
+	if err != nil {
+		reader, err := gzip.NewReader(r)
+		return reader, func() {}, err
+	}
+	err = resource.Value().Reset(r)
+	if err != nil {
+		resource.Release()
+		return nil, nil, err
+	}
+	return resource.Value(), resource.Release, nil
+}
@@ -2,13 +2,10 @@ package endpoint
 
 import (
 	"context"
-	"encoding/json"
 	"io"
-	"mime"
 	"net/http"
 
 	"github.com/pkg/errors"
-	"olympos.io/encoding/edn"
 )
 
 var (
@@ -29,6 +26,7 @@ type Response interface {
 type response struct {
 	Response *http.Response
 	ctx      context.Context
+	codecs   map[string]Codec
 	readed   bool
 }
 
@@ -37,53 +35,11 @@ func (r *response) Unmarshal(dest interface{}) error {
 	if err != nil {
 		return err
 	}
-	mediaType, _, err := mime.ParseMediaType(contentType)
+	codec, err := lookupCodec(r.codecs, contentType)
 	if err != nil {
-		return errors.Wrapf(err, "failed to parse media type for content-type - %s - %v", contentType, r.Headers())
-	}
-	switch mediaType {
-	case "application/json":
-		body, err := io.ReadAll(bodyReader)
-		if err != nil {
-			return errors.Wrap(err, "failed to read response body")
-		}
-		return errors.Wrap(json.Unmarshal(body, dest), "failed to unmarshal json body")
-	case "application/edn":
-		body, err := io.ReadAll(bodyReader)
-		if err != nil {
-			return errors.Wrap(err, "failed to read response body")
-		}
-		return errors.Wrap(edn.Unmarshal(body, dest), "failed to unmarshal edn body")
-	case "application/x-gzip":
-		switch v := dest.(type) {
-		case *[]byte:
-			gzipReader, err := gzipReaderPool.Acquire(r.ctx)
-			if err != nil {
-				return errors.Wrap(err, "failed to acquire gzip reader")
-			}
-			defer gzipReader.Release()
-			err = gzipReader.Value().Reset(bodyReader)
-			if err != nil {
-				return errors.Wrap(err, "failed to read response body")
-			}
-
-			readed := 0
-			buffer := make([]byte, 1024)
-			for err == nil {
-				readed, err = gzipReader.Value().Read(buffer)
-				if err != nil && !errors.Is(err, io.EOF) {
-					return errors.Wrap(err, "failed to read gziped content")
-				}
-				*v = append(*v, buffer[:readed]...)
-			}
-
-			return nil
-		default:
-			return errors.Wrap(ErrInvalidUnmarshalTarget, "unmapped")
-		}
-	default:
-		return errors.Wrap(ErrUnmappedMediaType, "unmapped")
+		return errors.Wrapf(err, "failed to find codec - %v", r.Headers())
 	}
+	return errors.Wrap(codec.Decode(bodyReader, dest), "failed to unmarshal body")
 }
 
 func (r *response) Status() int {
@@ -119,3 +75,15 @@ func (r *response) Close() error {
 	r.readed = true
 	return r.Response.Body.Close()
 }
+
+// maxDiscardSize bounds how much of an unwanted body is read so the
+// connection may be reused.
+const maxDiscardSize = 4 << 10
+
+func discardBody(res *http.Response) {
+	if res == nil || res.Body == nil {
+		return
+	}
+	_, _ = io.CopyN(io.Discard, res.Body, maxDiscardSize)
+	_ = res.Body.Close()
+}
@@ -0,0 +1,180 @@
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+type RetryDecisionFn func(res *http.Response, err error) bool
+
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	Jitter         float64
+	Methods        []string
+	ShouldRetry    RetryDecisionFn
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		Methods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodPut,
+			http.MethodDelete,
+			http.MethodOptions,
+			http.MethodTrace,
+		},
+		ShouldRetry: DefaultRetryDecision,
+	}
+}
+
+func DefaultRetryDecision(res *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) &&
+			!errors.Is(err, context.DeadlineExceeded)
+	}
+	switch res.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// RetryOnErrAnalyzer retries the responses errAnalyzer rejects, the body it
+// reads is restored so the response returned after the last attempt keeps it.
+func RetryOnErrAnalyzer(errAnalyzer ErrAnalyzerFn) RetryDecisionFn {
+	return func(res *http.Response, err error) bool {
+		if err != nil {
+			return DefaultRetryDecision(res, err)
+		}
+		content, err := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		if err != nil {
+			return true
+		}
+		res.Body = io.NopCloser(bytes.NewReader(content))
+		retry := errAnalyzer(res) != nil
+		res.Body = io.NopCloser(bytes.NewReader(content))
+		return retry
+	}
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		backoff += backoff * p.Jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(backoff)
+}
+
+// delay reports false when the server asks to wait longer than MaxBackoff, the
+// response is then returned instead of blocking the call.
+func (p *RetryPolicy) delay(attempt int, res *http.Response) (time.Duration, bool) {
+	if res != nil {
+		if retryAfter, ok := requester.ParseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+			return retryAfter, p.MaxBackoff <= 0 || retryAfter <= p.MaxBackoff
+		}
+	}
+	return p.backoff(attempt), true
+}
+
+type withRetryEndpointOption struct {
+	policy RetryPolicy
+}
+
+func (o *withRetryEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	if o.policy.MaxAttempts < 1 {
+		return errors.New("endpoint: retry max attempts must be at least 1")
+	}
+	opts.retryPolicy = &o.policy
+	return nil
+}
+
+func WithRetry(policy RetryPolicy) EndpointOption {
+	if policy.Multiplier == 0 {
+		policy.Multiplier = 1
+	}
+	if policy.ShouldRetry == nil {
+		policy.ShouldRetry = DefaultRetryDecision
+	}
+	if policy.Methods == nil {
+		policy.Methods = DefaultRetryPolicy().Methods
+	}
+	return &withRetryEndpointOption{
+		policy: policy,
+	}
+}
+
+func (e *endpoint) execute(
+	ctx context.Context,
+	method string,
+	opts *endpointOptions,
+) (*http.Response, int, error) {
+	policy := opts.retryPolicy
+	if policy == nil || !slices.Contains(policy.Methods, method) {
+		res, err := e.attempt(ctx, method, opts)
+		return res, 1, err
+	}
+
+	_, seekable := opts.body.(io.Seeker)
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && seekable {
+			_, err := opts.body.(io.Seeker).Seek(0, io.SeekStart)
+			if err != nil {
+				return nil, attempt, errors.Wrap(err, "failed to reset body reader")
+			}
+		}
+
+		res, err := e.attempt(ctx, method, opts)
+		replayable := opts.body == nil || (seekable && isReplayable(opts.body))
+		if attempt >= policy.MaxAttempts || !replayable || ctx.Err() != nil {
+			return res, attempt, err
+		}
+		if !policy.ShouldRetry(res, err) {
+			return res, attempt, err
+		}
+
+		delay, ok := policy.delay(attempt, res)
+		if !ok {
+			return res, attempt, err
+		}
+		discardBody(res)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempt, errors.Wrap(ctx.Err(), "endpoint: retry interrupted")
+		case <-timer.C:
+		}
+	}
+}
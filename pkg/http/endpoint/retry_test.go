@@ -0,0 +1,182 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/httptest"
+)
+
+func Test_Endpoint_WithRetry(t *testing.T) {
+	server := httptest.New(t)
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as/{paramA}",
+		server.Requester(),
+		WithRetry(policy),
+	)
+
+	expected := someType{
+		ID: 42,
+	}
+
+	server.
+		Body([]byte(`{"id":42}`)).
+		Put("/api/v1/as/some_value").
+		Return(
+			503,
+			[]byte(`Unavailable`),
+			http.Header{
+				"Retry-After": []string{"0"},
+			},
+		).
+		Return(
+			429,
+			[]byte(`Too Many Requests`),
+			http.Header{},
+		).
+		ReturnJSON(
+			200,
+			expected,
+			http.Header{},
+		)
+
+	response, err := end.Put(context.Background(),
+		WithParam("paramA", "some_value"),
+		WithJSONBody(expected),
+	)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	assert.Equal(t, 200, response.Status())
+
+	result := someType{}
+
+	err = response.Unmarshal(&result)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	assert.Equal(t, expected, result)
+}
+
+func Test_Endpoint_WithRetry_NonIdempotent(t *testing.T) {
+	server := httptest.New(t)
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as",
+		server.Requester(),
+		WithRetry(policy),
+		WithNotFoundErrAnalyzer(),
+	)
+
+	server.
+		Post("/api/v1/as").
+		Return(
+			503,
+			[]byte(`Unavailable`),
+			http.Header{},
+		)
+
+	response, err := end.Post(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	assert.Equal(t, 503, response.Status())
+}
+
+func Test_Endpoint_WithRetry_RetryAfterOverMaxBackoff(t *testing.T) {
+	server := httptest.New(t)
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as",
+		server.Requester(),
+		WithRetry(policy),
+		WithNotFoundErrAnalyzer(),
+	)
+
+	server.
+		Get("/api/v1/as").
+		Return(
+			503,
+			[]byte(`Unavailable`),
+			http.Header{
+				"Retry-After": []string{"86400"},
+			},
+		)
+
+	response, err := end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	assert.Equal(t, 503, response.Status())
+}
+
+func Test_Endpoint_WithRetry_DefaultMethods(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as",
+		server.Requester(),
+		WithRetry(RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+		}),
+		WithNotFoundErrAnalyzer(),
+	)
+
+	server.
+		Get("/api/v1/as").
+		Return(503, []byte(`Unavailable`), http.Header{}).
+		Return(503, []byte(`Unavailable`), http.Header{}).
+		Return(200, []byte(`{}`), http.Header{})
+
+	response, err := end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	assert.Equal(t, 200, response.Status())
+}
+
+func Test_Endpoint_WithRetry_ErrAnalyzerKeepsBody(t *testing.T) {
+	server := httptest.New(t)
+	policy := DefaultRetryPolicy()
+	policy.ShouldRetry = RetryOnErrAnalyzer(defaultErrAnalyzer)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as",
+		server.Requester(),
+		WithRetry(policy),
+	)
+
+	server.
+		Get("/api/v1/as").
+		Return(
+			503,
+			[]byte(`Maintenance`),
+			http.Header{
+				"Retry-After": []string{"86400"},
+			},
+		)
+
+	_, err := end.Get(context.Background())
+	httpErr, ok := AsHTTPError(err)
+	if !assert.True(t, ok) {
+		return
+	}
+	assert.Equal(t, "Maintenance", string(httpErr.Body))
+}
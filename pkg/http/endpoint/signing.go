@@ -0,0 +1,415 @@
+package endpoint
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+var ErrUnreadableBody = errors.New("endpoint: request body can not be read without consuming it")
+
+func requestBodyHash(request *http.Request) (string, error) {
+	hasher := sha256.New()
+	if request.Body != nil && request.Body != http.NoBody {
+		if request.GetBody == nil {
+			return "", errors.WithStack(ErrUnreadableBody)
+		}
+		body, err := request.GetBody()
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get request body")
+		}
+		defer body.Close()
+		_, err = io.Copy(hasher, body)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read request body")
+		}
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// canonicalQuery sorts the encoded pairs by key and then by value, sorting the
+// joined pairs would put "a-b=" before "a=".
+func canonicalQuery(query url.Values, escape func(string) string) string {
+	pairs := make([][2]string, 0, len(query))
+	for key, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, [2]string{escape(key), escape(value)})
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	joined := make([]string, len(pairs))
+	for idx, pair := range pairs {
+		joined[idx] = fmt.Sprintf("%s=%s", pair[0], pair[1])
+	}
+	return strings.Join(joined, "&")
+}
+
+func canonicalHeaderValue(values []string) string {
+	trimmed := make([]string, len(values))
+	for idx, value := range values {
+		trimmed[idx] = strings.Join(strings.Fields(value), " ")
+	}
+	return strings.Join(trimmed, ",")
+}
+
+func requestHost(request *http.Request) string {
+	if request.Host != "" {
+		return request.Host
+	}
+	return request.URL.Host
+}
+
+type HMACCanonicalizeFn func(
+	request *http.Request,
+	signedHeaders []string,
+	timestamp string,
+	bodyHash string,
+) string
+
+func DefaultHMACCanonicalize(
+	request *http.Request,
+	signedHeaders []string,
+	timestamp string,
+	bodyHash string,
+) string {
+	sb := &strings.Builder{}
+	sb.WriteString(request.Method)
+	sb.WriteByte('\n')
+	sb.WriteString(request.URL.EscapedPath())
+	sb.WriteByte('\n')
+	sb.WriteString(canonicalQuery(request.URL.Query(), url.QueryEscape))
+	sb.WriteByte('\n')
+	for _, header := range signedHeaders {
+		key := strings.ToLower(header)
+		value := canonicalHeaderValue(request.Header.Values(header))
+		if key == "host" {
+			value = requestHost(request)
+		}
+		sb.WriteString(fmt.Sprintf("%s:%s\n", key, value))
+	}
+	sb.WriteString(timestamp)
+	sb.WriteByte('\n')
+	sb.WriteString(bodyHash)
+	return sb.String()
+}
+
+type hmacSignatureOptions struct {
+	hash            func() hash.Hash
+	signatureHeader string
+	timestampHeader string
+	keyIDHeader     string
+	signedHeaders   []string
+	canonicalize    HMACCanonicalizeFn
+	encode          func(data []byte) string
+	now             func() time.Time
+}
+
+type HMACSignatureOption func(opts *hmacSignatureOptions)
+
+func WithHMACHash(hash func() hash.Hash) HMACSignatureOption {
+	return func(opts *hmacSignatureOptions) {
+		opts.hash = hash
+	}
+}
+
+func WithHMACHeaderNames(signatureHeader string, timestampHeader string, keyIDHeader string) HMACSignatureOption {
+	return func(opts *hmacSignatureOptions) {
+		opts.signatureHeader = signatureHeader
+		opts.timestampHeader = timestampHeader
+		opts.keyIDHeader = keyIDHeader
+	}
+}
+
+func WithHMACSignedHeaders(headers ...string) HMACSignatureOption {
+	return func(opts *hmacSignatureOptions) {
+		opts.signedHeaders = headers
+	}
+}
+
+func WithHMACCanonicalize(canonicalize HMACCanonicalizeFn) HMACSignatureOption {
+	return func(opts *hmacSignatureOptions) {
+		opts.canonicalize = canonicalize
+	}
+}
+
+func WithHMACBase64Encoding() HMACSignatureOption {
+	return func(opts *hmacSignatureOptions) {
+		opts.encode = base64.StdEncoding.EncodeToString
+	}
+}
+
+type withHMACSignatureEndpointOption struct {
+	keyID  string
+	secret []byte
+	opts   *hmacSignatureOptions
+}
+
+func (o *withHMACSignatureEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	opts.authFn = o.sign
+	return nil
+}
+
+func (o *withHMACSignatureEndpointOption) sign(
+	ctx context.Context,
+	requester requester.Requester,
+	request *http.Request,
+) (*http.Response, error) {
+	bodyHash, err := requestBodyHash(request)
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(o.opts.now().Unix(), 10)
+	if o.opts.timestampHeader != "" {
+		request.Header.Set(o.opts.timestampHeader, timestamp)
+	}
+	if o.opts.keyIDHeader != "" {
+		request.Header.Set(o.opts.keyIDHeader, o.keyID)
+	}
+	mac := hmac.New(o.opts.hash, o.secret)
+	mac.Write([]byte(o.opts.canonicalize(request, o.opts.signedHeaders, timestamp, bodyHash)))
+	request.Header.Set(o.opts.signatureHeader, o.opts.encode(mac.Sum(nil)))
+	return nil, nil
+}
+
+func WithHMACSignature(
+	keyID string,
+	secret []byte,
+	options ...HMACSignatureOption,
+) EndpointOption {
+	opts := &hmacSignatureOptions{
+		hash:            sha256.New,
+		signatureHeader: "X-Signature",
+		timestampHeader: "X-Signature-Timestamp",
+		keyIDHeader:     "X-Signature-Key-Id",
+		signedHeaders:   []string{"Host", "Content-Type"},
+		canonicalize:    DefaultHMACCanonicalize,
+		encode:          hex.EncodeToString,
+		now:             time.Now,
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	return &withHMACSignatureEndpointOption{
+		keyID:  keyID,
+		secret: secret,
+		opts:   opts,
+	}
+}
+
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+type AWSCredentialsProvider interface {
+	Retrieve(ctx context.Context) (AWSCredentials, error)
+}
+
+type AWSCredentialsProviderFn func(ctx context.Context) (AWSCredentials, error)
+
+func (fn AWSCredentialsProviderFn) Retrieve(ctx context.Context) (AWSCredentials, error) {
+	return fn(ctx)
+}
+
+func StaticAWSCredentials(
+	accessKeyID string,
+	secretAccessKey string,
+	sessionToken string,
+) AWSCredentialsProvider {
+	return AWSCredentialsProviderFn(func(ctx context.Context) (AWSCredentials, error) {
+		return AWSCredentials{
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+			SessionToken:    sessionToken,
+		}, nil
+	})
+}
+
+const (
+	awsSigV4Algorithm  = "AWS4-HMAC-SHA256"
+	awsSigV4TimeFormat = "20060102T150405Z"
+	awsSigV4DateFormat = "20060102"
+)
+
+var awsSigV4IgnoredHeaders = map[string]struct{}{
+	"authorization":   {},
+	"user-agent":      {},
+	"x-amzn-trace-id": {},
+	"expect":          {},
+	"content-length":  {},
+	// propagation headers may be rewritten by proxies along the way
+	"x-request-id": {},
+	"traceparent":  {},
+}
+
+func awsEscape(s string) string {
+	sb := &strings.Builder{}
+	for _, b := range []byte(s) {
+		if (b >= 'A' && b <= 'Z') ||
+			(b >= 'a' && b <= 'z') ||
+			(b >= '0' && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' {
+			sb.WriteByte(b)
+		} else {
+			sb.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+	}
+	return sb.String()
+}
+
+func awsCanonicalURI(u *url.URL, doubleEncode bool) string {
+	path := u.Path
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for idx, segment := range segments {
+		segments[idx] = awsEscape(segment)
+		if doubleEncode {
+			segments[idx] = awsEscape(segments[idx])
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func hmacSHA256(key []byte, content string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(content))
+	return mac.Sum(nil)
+}
+
+type withAWSSigV4EndpointOption struct {
+	region        string
+	service       string
+	credsProvider AWSCredentialsProvider
+	now           func() time.Time
+}
+
+func (o *withAWSSigV4EndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	opts.authFn = o.sign
+	return nil
+}
+
+func (o *withAWSSigV4EndpointOption) sign(
+	ctx context.Context,
+	requester requester.Requester,
+	request *http.Request,
+) (*http.Response, error) {
+	creds, err := o.credsProvider.Retrieve(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to retrieve aws credentials")
+	}
+	payloadHash, err := requestBodyHash(request)
+	if err != nil {
+		return nil, err
+	}
+
+	now := o.now().UTC()
+	amzDate := now.Format(awsSigV4TimeFormat)
+	scope := strings.Join([]string{
+		now.Format(awsSigV4DateFormat),
+		o.region,
+		o.service,
+		"aws4_request",
+	}, "/")
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	if o.service == "s3" {
+		request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	headers := map[string]string{
+		"host": requestHost(request),
+	}
+	for key, values := range request.Header {
+		key = strings.ToLower(key)
+		if _, ignored := awsSigV4IgnoredHeaders[key]; ignored {
+			continue
+		}
+		headers[key] = canonicalHeaderValue(values)
+	}
+	signedHeaders := make([]string, 0, len(headers))
+	for key := range headers {
+		signedHeaders = append(signedHeaders, key)
+	}
+	sort.Strings(signedHeaders)
+
+	canonicalHeaders := &strings.Builder{}
+	for _, key := range signedHeaders {
+		canonicalHeaders.WriteString(fmt.Sprintf("%s:%s\n", key, headers[key]))
+	}
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		awsCanonicalURI(request.URL, o.service != "s3"),
+		canonicalQuery(request.URL.Query(), awsEscape),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+
+	stringToSign := strings.Join([]string{
+		awsSigV4Algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), now.Format(awsSigV4DateFormat))
+	signingKey = hmacSHA256(signingKey, o.region)
+	signingKey = hmacSHA256(signingKey, o.service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		awsSigV4Algorithm,
+		creds.AccessKeyID,
+		scope,
+		strings.Join(signedHeaders, ";"),
+		hex.EncodeToString(hmacSHA256(signingKey, stringToSign)),
+	))
+	return nil, nil
+}
+
+func WithAWSSigV4(
+	region string,
+	service string,
+	credsProvider AWSCredentialsProvider,
+) EndpointOption {
+	return &withAWSSigV4EndpointOption{
+		region:        region,
+		service:       service,
+		credsProvider: credsProvider,
+		now:           time.Now,
+	}
+}
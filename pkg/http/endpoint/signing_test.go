@@ -0,0 +1,140 @@
+package endpoint
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+func capturingRequester(captured **http.Request) requester.Requester {
+	return requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		*captured = req
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       http.NoBody,
+			Header:     http.Header{},
+		}, nil
+	})
+}
+
+func Test_WithAWSSigV4(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		options  []EndpointOption
+		expected string
+	}{
+		{
+			name:     "should sign get vanilla request",
+			path:     "/",
+			expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name: "should sign get request with unordered query",
+			path: "/",
+			options: []EndpointOption{
+				WithQueryParam("Param2", "value2"),
+				WithQueryParam("Param1", "value1"),
+			},
+			expected: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sigV4 := WithAWSSigV4(
+				"us-east-1",
+				"service",
+				StaticAWSCredentials("AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", ""),
+			)
+			sigV4.(*withAWSSigV4EndpointOption).now = func() time.Time {
+				return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+			}
+
+			var captured *http.Request
+			end := NewEndpoint(
+				"http://example.amazonaws.com",
+				tt.path,
+				capturingRequester(&captured),
+				sigV4,
+			)
+
+			_, err := end.Get(context.Background(), tt.options...)
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			assert.Equal(t, tt.expected, captured.Header.Get("Authorization"))
+		})
+	}
+}
+
+func Test_WithHMACSignature(t *testing.T) {
+	secret := []byte("secret")
+	signature := WithHMACSignature("key", secret)
+	signature.(*withHMACSignatureEndpointOption).opts.now = func() time.Time {
+		return time.Unix(1700000000, 0)
+	}
+
+	var captured *http.Request
+	end := NewEndpoint(
+		"http://example.com",
+		"/api/v1/as",
+		capturingRequester(&captured),
+		signature,
+	)
+
+	_, err := end.Post(context.Background(),
+		WithQueryParam("b", "2"),
+		WithQueryParam("a", "1"),
+		WithJSONBody(someType{ID: 42}),
+	)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	bodyHash := sha256.Sum256([]byte(`{"id":42}`))
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("POST\n/api/v1/as\na=1&b=2\nhost:example.com\ncontent-type:application/json\n1700000000\n" + hex.EncodeToString(bodyHash[:])))
+
+	assert.Equal(t, "1700000000", captured.Header.Get("X-Signature-Timestamp"))
+	assert.Equal(t, "key", captured.Header.Get("X-Signature-Key-Id"))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), captured.Header.Get("X-Signature"))
+}
+
+func Test_canonicalQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    url.Values
+		expected string
+	}{
+		{
+			name: "should sort key prefixes first",
+			query: url.Values{
+				"a-b": []string{"2"},
+				"a":   []string{"1"},
+			},
+			expected: "a=1&a-b=2",
+		},
+		{
+			name: "should sort values of the same key",
+			query: url.Values{
+				"b": []string{"2", "1"},
+				"a": []string{"z"},
+			},
+			expected: "a=z&b=1&b=2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, canonicalQuery(tt.query, awsEscape))
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package endpoint
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrNotEventStream = errors.New("endpoint: response is not an event stream")
+
+const (
+	eventStreamMediaType  = "text/event-stream"
+	defaultReconnectDelay = 3 * time.Second
+)
+
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration
+
+	codecs map[string]Codec
+}
+
+// Unmarshal decodes the event data as JSON.
+func (e Event) Unmarshal(dest any) error {
+	return e.UnmarshalAs("application/json", dest)
+}
+
+func (e Event) UnmarshalAs(mediaType string, dest any) error {
+	codec, err := lookupCodec(e.codecs, mediaType)
+	if err != nil {
+		return errors.Wrap(err, "failed to find codec")
+	}
+	return errors.Wrap(codec.Decode(strings.NewReader(e.Data), dest), "failed to unmarshal event data")
+}
+
+type eventStreamOpenFn func(ctx context.Context, lastEventID string) (Response, error)
+
+type EventStream struct {
+	ctx            context.Context
+	cancel         context.CancelFunc
+	open           eventStreamOpenFn
+	body           io.Closer
+	reader         *bufio.Reader
+	codecs         map[string]Codec
+	event          Event
+	idBuffer       string
+	lastEventID    string
+	reconnectDelay time.Duration
+	err            error
+	closed         bool
+}
+
+func (s *EventStream) attach(res Response) error {
+	contentType, body, err := res.RawBodyStream()
+	if err != nil {
+		return err
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != eventStreamMediaType {
+		_ = res.Close()
+		return errors.Wrapf(ErrNotEventStream, "content-type - %s", contentType)
+	}
+	if r, ok := res.(*response); ok {
+		s.codecs = r.codecs
+	}
+	s.body = res
+	s.reader = bufio.NewReader(body)
+	s.idBuffer = s.lastEventID
+	return nil
+}
+
+// readEvent returns false when the connection ends, an event still being
+// received at that point is discarded.
+func (s *EventStream) readEvent() (bool, error) {
+	data := bytes.Buffer{}
+	event := Event{}
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return false, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if data.Len() == 0 {
+				event = Event{}
+				continue
+			}
+			s.lastEventID = s.idBuffer
+			event.ID = s.lastEventID
+			event.Data = strings.TrimSuffix(data.String(), "\n")
+			if event.Event == "" {
+				event.Event = "message"
+			}
+			event.codecs = s.codecs
+			s.event = event
+			return true, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteString("\n")
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				s.idBuffer = value
+			}
+		case "retry":
+			milliseconds, err := strconv.ParseUint(value, 10, 63)
+			if err == nil {
+				event.Retry = time.Duration(milliseconds) * time.Millisecond
+				s.reconnectDelay = event.Retry
+			}
+		}
+	}
+}
+
+func (s *EventStream) wait() bool {
+	timer := time.NewTimer(s.reconnectDelay)
+	defer timer.Stop()
+	select {
+	case <-s.ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (s *EventStream) reconnect() bool {
+	for s.wait() {
+		res, err := s.open(s.ctx, s.lastEventID)
+		if err == nil {
+			if res.Status() == http.StatusNoContent {
+				// the server asked the client to stop reconnecting
+				_ = res.Close()
+				return false
+			}
+			err = s.attach(res)
+			if err == nil {
+				return true
+			}
+		}
+		if _, ok := AsHTTPError(err); ok || errors.Is(err, ErrNotEventStream) {
+			s.err = err
+			return false
+		}
+	}
+	return false
+}
+
+func (s *EventStream) Next() bool {
+	for !s.closed && s.err == nil && s.reader != nil {
+		ok, err := s.readEvent()
+		if ok {
+			return true
+		}
+		_ = s.body.Close()
+		s.reader = nil
+		if s.ctx.Err() != nil {
+			return false
+		}
+		if s.open == nil {
+			if err != io.EOF {
+				s.err = errors.Wrap(err, "failed to read event stream")
+			}
+			return false
+		}
+		if !s.reconnect() {
+			return false
+		}
+	}
+	return false
+}
+
+func (s *EventStream) Event() Event {
+	return s.event
+}
+
+func (s *EventStream) LastEventID() string {
+	return s.lastEventID
+}
+
+func (s *EventStream) Err() error {
+	return s.err
+}
+
+func (s *EventStream) Close() error {
+	s.closed = true
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if s.reader != nil {
+		s.reader = nil
+		return s.body.Close()
+	}
+	return nil
+}
+
+// NewEventStream decodes the events of an already received response, without
+// reconnecting when it ends.
+func NewEventStream(res Response) (*EventStream, error) {
+	s := &EventStream{
+		ctx:            context.Background(),
+		reconnectDelay: defaultReconnectDelay,
+	}
+	if r, ok := res.(*response); ok && r.ctx != nil {
+		s.ctx = r.ctx
+	}
+	err := s.attach(res)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SubscribeEvents keeps a GET open on the endpoint, reconnecting with the
+// Last-Event-ID header when the connection drops until ctx is done, the
+// stream is closed or the server answers with an error or 204.
+func SubscribeEvents(
+	ctx context.Context,
+	end Endpoint,
+	options ...EndpointOption,
+) (*EventStream, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &EventStream{
+		ctx:            ctx,
+		cancel:         cancel,
+		reconnectDelay: defaultReconnectDelay,
+	}
+	s.open = func(ctx context.Context, lastEventID string) (Response, error) {
+		reqOptions := append([]EndpointOption{
+			WithHeaderParam("Accept", eventStreamMediaType),
+			WithHeaderParam("Cache-Control", "no-cache"),
+		}, options...)
+		if lastEventID != "" {
+			reqOptions = append(reqOptions, WithHeaderParam("Last-Event-ID", lastEventID))
+		}
+		return end.Get(ctx, reqOptions...)
+	}
+
+	res, err := s.open(ctx, "")
+	if err == nil {
+		err = s.attach(res)
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return s, nil
+}
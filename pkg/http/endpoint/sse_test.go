@@ -0,0 +1,104 @@
+package endpoint
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+func eventStreamResponse(status int, body string) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header: http.Header{
+			"Content-Type": []string{"text/event-stream; charset=utf-8"},
+		},
+		Body: io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func Test_SubscribeEvents(t *testing.T) {
+	lastEventIDs := []string{}
+	connections := []*http.Response{
+		eventStreamResponse(200, "retry: 1\n"+
+			": keep alive\n"+
+			"id: 1\n"+
+			"data: {\"value\":1}\n"+
+			"\n"+
+			"event: multi\n"+
+			"data: line1\r\n"+
+			"data: line2\n"+
+			"\n"+
+			"id: 2\n"+
+			"data: partial"),
+		eventStreamResponse(200, "id: 3\ndata: {\"value\":3}\n\n"),
+		eventStreamResponse(204, ""),
+	}
+	end := NewEndpoint(
+		"http://example.com",
+		"/events",
+		requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+			assert.Equal(t, "text/event-stream", req.Header.Get("Accept"))
+			lastEventIDs = append(lastEventIDs, req.Header.Get("Last-Event-ID"))
+			res := connections[0]
+			connections = connections[1:]
+			return res, nil
+		}),
+	)
+
+	stream, err := SubscribeEvents(context.Background(), end)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	defer stream.Close()
+
+	events := []Event{}
+	for stream.Next() {
+		events = append(events, stream.Event())
+	}
+	if !assertutil.Error(t, nil, stream.Err()) || !assert.Len(t, events, 3) {
+		return
+	}
+	assert.Equal(t, []string{"", "1", "3"}, lastEventIDs)
+
+	assert.Equal(t, "1", events[0].ID)
+	assert.Equal(t, "message", events[0].Event)
+	value := struct {
+		Value int `json:"value"`
+	}{}
+	assertutil.Error(t, nil, events[0].Unmarshal(&value))
+	assert.Equal(t, 1, value.Value)
+
+	assert.Equal(t, "1", events[1].ID)
+	assert.Equal(t, "multi", events[1].Event)
+	assert.Equal(t, "line1\nline2", events[1].Data)
+
+	assert.Equal(t, "3", events[2].ID)
+	assertutil.Error(t, nil, events[2].Unmarshal(&value))
+	assert.Equal(t, 3, value.Value)
+}
+
+func Test_NewEventStream_NotEventStream(t *testing.T) {
+	end := NewEndpoint(
+		"http://example.com",
+		"/events",
+		requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader("{}")),
+			}, nil
+		}),
+	)
+	res, err := end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	_, err = NewEventStream(res)
+	assert.ErrorIs(t, err, ErrNotEventStream)
+}
@@ -0,0 +1,191 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"io"
+	"mime"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vitorsss/go-helpers/pkg/files"
+)
+
+var ErrNotJSONArray = errors.New("endpoint: response is not a json array")
+
+// StreamPosition locates an element in the response body, Line is only known
+// for line delimited formats.
+type StreamPosition struct {
+	Index  int
+	Line   int
+	Offset int64
+}
+
+type streamDecoder[T any] interface {
+	decode(dest *T) (StreamPosition, error)
+}
+
+type lineStreamDecoder[T any] struct {
+	decoder *files.LineDecoder[T]
+}
+
+func (d *lineStreamDecoder[T]) decode(dest *T) (StreamPosition, error) {
+	err := d.decoder.Decode(dest)
+	var lineErr *files.LineError
+	if errors.As(err, &lineErr) {
+		return StreamPosition{Line: lineErr.Line, Offset: lineErr.Offset}, err
+	}
+	return StreamPosition{Line: d.decoder.Line(), Offset: d.decoder.Offset()}, err
+}
+
+type arrayStreamDecoder[T any] struct {
+	decoder *json.Decoder
+	started bool
+}
+
+// elementError is an invalid element that was fully consumed, so the stream
+// can go on.
+type elementError struct {
+	err error
+}
+
+func (e *elementError) Error() string {
+	return e.err.Error()
+}
+
+func (e *elementError) Unwrap() error {
+	return e.err
+}
+
+// separatorSize counts the comma and spaces the decoder has not consumed yet
+// before the next element.
+func (d *arrayStreamDecoder[T]) separatorSize() int64 {
+	buffered, _ := io.ReadAll(d.decoder.Buffered())
+	size := 0
+	for size < len(buffered) && strings.IndexByte(" \t\r\n,", buffered[size]) >= 0 {
+		size++
+	}
+	return int64(size)
+}
+
+func (d *arrayStreamDecoder[T]) decode(dest *T) (StreamPosition, error) {
+	if !d.started {
+		d.started = true
+		token, err := d.decoder.Token()
+		if err != nil {
+			return StreamPosition{}, errors.Wrap(err, "failed to read json array")
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return StreamPosition{}, errors.Wrapf(ErrNotJSONArray, "starts with %v", token)
+		}
+	}
+	if !d.decoder.More() {
+		_, err := d.decoder.Token()
+		if err != nil {
+			return StreamPosition{}, errors.Wrap(err, "failed to read json array")
+		}
+		return StreamPosition{}, io.EOF
+	}
+
+	position := StreamPosition{Offset: d.decoder.InputOffset() + d.separatorSize()}
+	var content T
+	err := d.decoder.Decode(&content)
+	if err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return position, &elementError{err: err}
+		}
+		return position, errors.Wrap(err, "failed to decode json array")
+	}
+	*dest = content
+	return position, nil
+}
+
+// ItemStream decodes one element at a time, Next returns true with a non nil
+// Err for an element that could not be decoded and false once the stream ends
+// or cannot be read anymore.
+type ItemStream[T any] struct {
+	body     io.Closer
+	decoder  streamDecoder[T]
+	item     T
+	position StreamPosition
+	index    int
+	err      error
+	done     bool
+}
+
+func (s *ItemStream[T]) Next() bool {
+	if s.done {
+		return false
+	}
+	var item T
+	position, err := s.decoder.decode(&item)
+	position.Index = s.index
+	s.position = position
+	s.item = item
+	s.err = nil
+	if err == io.EOF {
+		s.done = true
+		_ = s.body.Close()
+		return false
+	}
+	var lineErr *files.LineError
+	var elementErr *elementError
+	if err != nil && !errors.As(err, &lineErr) && !errors.As(err, &elementErr) {
+		s.done = true
+		s.err = err
+		_ = s.body.Close()
+		return false
+	}
+	s.index++
+	s.err = err
+	return true
+}
+
+func (s *ItemStream[T]) Item() T {
+	return s.item
+}
+
+func (s *ItemStream[T]) Position() StreamPosition {
+	return s.position
+}
+
+func (s *ItemStream[T]) Err() error {
+	return s.err
+}
+
+func (s *ItemStream[T]) Close() error {
+	s.done = true
+	return s.body.Close()
+}
+
+// Stream decodes NDJSON, NDEDN and top level JSON array responses element by
+// element, without reading the whole body in memory.
+func Stream[T any](res Response) (*ItemStream[T], error) {
+	contentType, body, err := res.RawBodyStream()
+	if err != nil {
+		return nil, err
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		_ = res.Close()
+		return nil, errors.Wrapf(err, "failed to parse media type for content-type - %s", contentType)
+	}
+
+	var decoder streamDecoder[T]
+	switch {
+	case mediaType == "application/x-ndjson" || mediaType == "application/ndjson":
+		decoder = &lineStreamDecoder[T]{decoder: files.NewNDJSONDecoder[T](body)}
+	case mediaType == "application/x-ndedn":
+		decoder = &lineStreamDecoder[T]{decoder: files.NewNDEDNDecoder[T](body)}
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		decoder = &arrayStreamDecoder[T]{decoder: json.NewDecoder(body)}
+	default:
+		_ = res.Close()
+		return nil, errors.Wrapf(ErrUnmappedMediaType, "unmapped - %s", mediaType)
+	}
+
+	return &ItemStream[T]{
+		body:    res,
+		decoder: decoder,
+	}, nil
+}
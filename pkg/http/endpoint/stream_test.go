@@ -0,0 +1,112 @@
+package endpoint
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+type streamItem struct {
+	ID int `json:"id" edn:"id"`
+}
+
+func Test_Stream(t *testing.T) {
+	tests := []struct {
+		name          string
+		contentType   string
+		body          string
+		wantItems     []streamItem
+		wantPositions []StreamPosition
+		wantElemErrs  []bool
+		wantErr       bool
+	}{
+		{
+			name:        "ndjson",
+			contentType: "application/x-ndjson",
+			body:        "{\"id\":1}\n{\"id\":\"x\"}\n\n{\"id\":3}\n",
+			wantItems:   []streamItem{{ID: 1}, {}, {ID: 3}},
+			wantPositions: []StreamPosition{
+				{Index: 0, Line: 1, Offset: 0},
+				{Index: 1, Line: 2, Offset: 9},
+				{Index: 2, Line: 4, Offset: 21},
+			},
+			wantElemErrs: []bool{false, true, false},
+		},
+		{
+			name:        "ndedn",
+			contentType: "application/x-ndedn",
+			body:        "{:id 1}\n{:id 2}",
+			wantItems:   []streamItem{{ID: 1}, {ID: 2}},
+			wantPositions: []StreamPosition{
+				{Index: 0, Line: 1, Offset: 0},
+				{Index: 1, Line: 2, Offset: 8},
+			},
+			wantElemErrs: []bool{false, false},
+		},
+		{
+			name:        "json array",
+			contentType: "application/json; charset=utf-8",
+			body:        `[{"id":1},{"id":"x"}, {"id":3}]`,
+			wantItems:   []streamItem{{ID: 1}, {}, {ID: 3}},
+			wantPositions: []StreamPosition{
+				{Index: 0, Offset: 1},
+				{Index: 1, Offset: 10},
+				{Index: 2, Offset: 22},
+			},
+			wantElemErrs: []bool{false, true, false},
+		},
+		{
+			name:         "truncated json array",
+			contentType:  "application/json",
+			body:         `[{"id":1},{"id"`,
+			wantItems:    []streamItem{{ID: 1}},
+			wantElemErrs: []bool{false},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := NewEndpoint(
+				"http://example.com",
+				"/export",
+				requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+					return &http.Response{
+						StatusCode: 200,
+						Header:     http.Header{"Content-Type": []string{tt.contentType}},
+						Body:       io.NopCloser(strings.NewReader(tt.body)),
+					}, nil
+				}),
+			)
+			res, err := end.Get(context.Background())
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			stream, err := Stream[streamItem](res)
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			defer stream.Close()
+
+			items := []streamItem{}
+			positions := []StreamPosition{}
+			elemErrs := []bool{}
+			for stream.Next() {
+				items = append(items, stream.Item())
+				positions = append(positions, stream.Position())
+				elemErrs = append(elemErrs, stream.Err() != nil)
+			}
+			assert.Equal(t, tt.wantItems, items)
+			assert.Equal(t, tt.wantElemErrs, elemErrs)
+			if tt.wantPositions != nil {
+				assert.Equal(t, tt.wantPositions, positions)
+			}
+			assert.Equal(t, tt.wantErr, stream.Err() != nil)
+		})
+	}
+}
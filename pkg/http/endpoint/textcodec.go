@@ -0,0 +1,121 @@
+package endpoint
+
+import (
+	"encoding/csv"
+	"io"
+	"net/url"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+type csvCodec struct{}
+
+func (c *csvCodec) Encode(w io.Writer, v any) error {
+	var records [][]string
+	switch content := v.(type) {
+	case [][]string:
+		records = content
+	case []map[string]string:
+		headerSet := map[string]struct{}{}
+		for _, row := range content {
+			for key := range row {
+				headerSet[key] = struct{}{}
+			}
+		}
+		headers := make([]string, 0, len(headerSet))
+		for key := range headerSet {
+			headers = append(headers, key)
+		}
+		sort.Strings(headers)
+		records = append(records, headers)
+		for _, row := range content {
+			record := make([]string, len(headers))
+			for idx, header := range headers {
+				record[idx] = row[header]
+			}
+			records = append(records, record)
+		}
+	default:
+		return errors.New("endpoint: expected [][]string or []map[string]string to encode csv content")
+	}
+	csvWriter := csv.NewWriter(w)
+	return csvWriter.WriteAll(records)
+}
+
+func (c *csvCodec) Decode(r io.Reader, v any) error {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return errors.Wrap(err, "failed to read csv content")
+	}
+	switch dest := v.(type) {
+	case *[][]string:
+		*dest = records
+	case *[]map[string]string:
+		if len(records) == 0 {
+			*dest = []map[string]string{}
+			return nil
+		}
+		headers := records[0]
+		result := make([]map[string]string, 0, len(records)-1)
+		for _, record := range records[1:] {
+			row := map[string]string{}
+			for idx, cell := range record {
+				if idx < len(headers) {
+					row[headers[idx]] = cell
+				}
+			}
+			result = append(result, row)
+		}
+		*dest = result
+	default:
+		return errors.Wrap(ErrInvalidUnmarshalTarget, "expected *[][]string or *[]map[string]string")
+	}
+	return nil
+}
+
+type formCodec struct{}
+
+func (c *formCodec) Encode(w io.Writer, v any) error {
+	values := url.Values{}
+	switch content := v.(type) {
+	case url.Values:
+		values = content
+	case map[string][]string:
+		values = content
+	case map[string]string:
+		for key, value := range content {
+			values.Set(key, value)
+		}
+	default:
+		return errors.New("endpoint: expected url.Values or map to encode form content")
+	}
+	_, err := io.WriteString(w, values.Encode())
+	return err
+}
+
+func (c *formCodec) Decode(r io.Reader, v any) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to read response body")
+	}
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return errors.Wrap(err, "failed to parse form content")
+	}
+	switch dest := v.(type) {
+	case *url.Values:
+		*dest = values
+	case *map[string][]string:
+		*dest = values
+	case *map[string]string:
+		result := make(map[string]string, len(values))
+		for key := range values {
+			result[key] = values.Get(key)
+		}
+		*dest = result
+	default:
+		return errors.Wrap(ErrInvalidUnmarshalTarget, "expected *url.Values or map pointer")
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package endpoint
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+var (
+	ErrFirstByteTimeout = errors.New("endpoint: first byte timeout")
+	ErrIdleTimeout      = errors.New("endpoint: body idle timeout")
+)
+
+type withTimeoutEndpointOption struct {
+	timeout time.Duration
+}
+
+func (o *withTimeoutEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	opts.timeout = o.timeout
+	return nil
+}
+
+// WithTimeout limits the whole call, auth round trips, retries and the
+// response body reading included. The call context is released once the
+// response is closed.
+func WithTimeout(timeout time.Duration) EndpointOption {
+	return &withTimeoutEndpointOption{
+		timeout: timeout,
+	}
+}
+
+type withFirstByteTimeoutEndpointOption struct {
+	timeout time.Duration
+}
+
+func (o *withFirstByteTimeoutEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	opts.firstByteTimeout = o.timeout
+	return nil
+}
+
+// WithFirstByteTimeout limits how long each request sent by the call waits for
+// the response headers, a timed out attempt fails with ErrFirstByteTimeout and
+// may be retried.
+func WithFirstByteTimeout(timeout time.Duration) EndpointOption {
+	return &withFirstByteTimeoutEndpointOption{
+		timeout: timeout,
+	}
+}
+
+type withIdleTimeoutEndpointOption struct {
+	timeout time.Duration
+}
+
+func (o *withIdleTimeoutEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	opts.idleTimeout = o.timeout
+	return nil
+}
+
+// WithIdleTimeout fails reads of the response body with ErrIdleTimeout when
+// no data arrives for the given time, it keeps applying while the stream
+// returned by RawBodyStream is consumed.
+func WithIdleTimeout(timeout time.Duration) EndpointOption {
+	return &withIdleTimeoutEndpointOption{
+		timeout: timeout,
+	}
+}
+
+func (o *endpointOptions) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, o.timeout)
+}
+
+// cancelBody releases the call context once the response body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func timeoutCause(ctx context.Context, err error) error {
+	cause := context.Cause(ctx)
+	if errors.Is(cause, ErrFirstByteTimeout) || errors.Is(cause, ErrIdleTimeout) {
+		return errors.Wrap(cause, err.Error())
+	}
+	return err
+}
+
+type attemptTimeouts struct {
+	firstByte time.Duration
+	idle      time.Duration
+}
+
+func (t *attemptTimeouts) wrap(client requester.Requester) requester.Requester {
+	return requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		ctx, cancel := context.WithCancelCause(req.Context())
+		req = req.WithContext(ctx)
+
+		var timer *time.Timer
+		if t.firstByte > 0 {
+			timer = time.AfterFunc(t.firstByte, func() {
+				cancel(ErrFirstByteTimeout)
+			})
+		}
+		res, err := client.Do(req)
+		if timer != nil && !timer.Stop() && err == nil {
+			// the timer fired while the response was returned
+			err = context.Cause(ctx)
+			if res.Body != nil {
+				_ = res.Body.Close()
+			}
+		}
+		if err != nil {
+			err = timeoutCause(ctx, err)
+			cancel(nil)
+			return nil, err
+		}
+		if res.Body == nil {
+			cancel(nil)
+			return res, nil
+		}
+		res.Body = &timeoutBody{
+			ReadCloser: res.Body,
+			ctx:        ctx,
+			cancel:     cancel,
+			idle:       t.idle,
+		}
+		return res, nil
+	})
+}
+
+// timeoutBody arms the idle timer while each read waits for data and keeps the
+// attempt context alive until the body is closed.
+type timeoutBody struct {
+	io.ReadCloser
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	idle   time.Duration
+	lock   sync.Mutex
+	timer  *time.Timer
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	if b.idle > 0 {
+		b.lock.Lock()
+		if b.timer == nil {
+			b.timer = time.AfterFunc(b.idle, func() {
+				b.cancel(ErrIdleTimeout)
+			})
+		} else {
+			b.timer.Reset(b.idle)
+		}
+		b.lock.Unlock()
+	}
+	n, err := b.ReadCloser.Read(p)
+	if b.idle > 0 {
+		b.lock.Lock()
+		b.timer.Stop()
+		b.lock.Unlock()
+	}
+	if err != nil && err != io.EOF {
+		err = timeoutCause(b.ctx, err)
+	}
+	return n, err
+}
+
+func (b *timeoutBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.lock.Lock()
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.lock.Unlock()
+	b.cancel(nil)
+	return err
+}
@@ -0,0 +1,143 @@
+package endpoint
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/requester"
+)
+
+// stallingReader sends its chunks with the given pause between them and
+// blocks once they are over until the request context is done.
+type stallingReader struct {
+	ctx    context.Context
+	chunks []string
+	pause  time.Duration
+}
+
+func (r *stallingReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		<-r.ctx.Done()
+		return 0, r.ctx.Err()
+	}
+	time.Sleep(r.pause)
+	n := copy(p, r.chunks[0])
+	r.chunks = r.chunks[1:]
+	return n, nil
+}
+
+func Test_Endpoint_WithTimeout(t *testing.T) {
+	var reqCtx context.Context
+	end := NewEndpoint(
+		"http://example.com",
+		"/items",
+		requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+			reqCtx = req.Context()
+			if req.Method == http.MethodPost {
+				<-reqCtx.Done()
+				return nil, reqCtx.Err()
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}),
+		WithTimeout(20*time.Millisecond),
+	)
+
+	res, err := end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.NoError(t, reqCtx.Err())
+	_ = res.Close()
+	assert.ErrorIs(t, reqCtx.Err(), context.Canceled)
+
+	_, err = end.Post(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func Test_Endpoint_WithFirstByteTimeout(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+
+	tests := []struct {
+		name     string
+		options  []EndpointOption
+		attempts int
+		err      error
+	}{
+		{
+			name:     "fails the attempt",
+			attempts: 1,
+			err:      ErrFirstByteTimeout,
+		},
+		{
+			name:     "retries the attempt",
+			options:  []EndpointOption{WithRetry(policy)},
+			attempts: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			end := NewEndpoint(
+				"http://example.com",
+				"/items",
+				requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+					attempts++
+					if attempts == 1 {
+						<-req.Context().Done()
+						return nil, req.Context().Err()
+					}
+					return &http.Response{
+						StatusCode: http.StatusOK,
+						Body:       io.NopCloser(strings.NewReader("")),
+					}, nil
+				}),
+				append(tt.options, WithFirstByteTimeout(10*time.Millisecond))...,
+			)
+
+			_, err := end.Get(context.Background())
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.attempts, attempts)
+		})
+	}
+}
+
+func Test_Endpoint_WithIdleTimeout(t *testing.T) {
+	end := NewEndpoint(
+		"http://example.com",
+		"/items",
+		requester.RequesterFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body: io.NopCloser(&stallingReader{
+					ctx:    req.Context(),
+					chunks: []string{"a", "b", "c", "d"},
+					pause:  10 * time.Millisecond,
+				}),
+			}, nil
+		}),
+		WithIdleTimeout(30*time.Millisecond),
+	)
+
+	res, err := end.Get(context.Background())
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	defer res.Close()
+	_, body, err := res.RawBodyStream()
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	data, err := io.ReadAll(body)
+	assert.ErrorIs(t, err, ErrIdleTimeout)
+	assert.Equal(t, "abcd", string(data))
+}
@@ -0,0 +1,138 @@
+package endpoint
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var traceParentRegex = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+type Span struct {
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Flags        string
+	Name         string
+	Start        time.Time
+	End          time.Time
+	Attributes   map[string]string
+	Err          error
+}
+
+func (s *Span) TraceParent() string {
+	return fmt.Sprintf("00-%s-%s-%s", s.TraceID, s.SpanID, s.Flags)
+}
+
+type SpanExporter interface {
+	Export(span Span)
+}
+
+type InMemorySpanExporter struct {
+	lock  sync.Mutex
+	spans []Span
+}
+
+func NewInMemorySpanExporter() *InMemorySpanExporter {
+	return &InMemorySpanExporter{}
+}
+
+func (e *InMemorySpanExporter) Export(span Span) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+func (e *InMemorySpanExporter) Spans() []Span {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return append([]Span{}, e.spans...)
+}
+
+type traceParentContextKey struct{}
+
+type spanContextKey struct{}
+
+func ContextWithTraceParent(ctx context.Context, traceParent string) context.Context {
+	return context.WithValue(ctx, traceParentContextKey{}, traceParent)
+}
+
+func TraceParentFromContext(ctx context.Context) string {
+	if span, ok := ctx.Value(spanContextKey{}).(*Span); ok {
+		return span.TraceParent()
+	}
+	traceParent, _ := ctx.Value(traceParentContextKey{}).(string)
+	return traceParent
+}
+
+func randomHex(size int) string {
+	data := make([]byte, size)
+	_, _ = rand.Read(data)
+	return hex.EncodeToString(data)
+}
+
+type traceHook struct {
+	exporter SpanExporter
+}
+
+func (h *traceHook) Started(ctx context.Context, info CallInfo) context.Context {
+	span := &Span{
+		TraceID: randomHex(16),
+		SpanID:  randomHex(8),
+		Flags:   "01",
+		Name:    fmt.Sprintf("%s %s", info.Method, info.URLTemplate),
+		Start:   time.Now(),
+	}
+	if parent := traceParentRegex.FindStringSubmatch(TraceParentFromContext(ctx)); parent != nil {
+		span.TraceID = parent[1]
+		span.ParentSpanID = parent[2]
+		span.Flags = parent[3]
+	}
+	return context.WithValue(ctx, spanContextKey{}, span)
+}
+
+func (h *traceHook) BeforeRequest(req *http.Request) {
+	if span, ok := req.Context().Value(spanContextKey{}).(*Span); ok {
+		req.Header.Set("traceparent", span.TraceParent())
+	}
+}
+
+func (h *traceHook) end(ctx context.Context, info CallInfo) {
+	span, ok := ctx.Value(spanContextKey{}).(*Span)
+	if !ok {
+		return
+	}
+	span.End = span.Start.Add(info.Duration)
+	span.Err = info.Err
+	span.Attributes = map[string]string{
+		"http.method":       info.Method,
+		"http.url_template": info.URLTemplate,
+		"http.attempts":     strconv.Itoa(info.Attempts),
+	}
+	if info.Status != 0 {
+		span.Attributes["http.status_code"] = strconv.Itoa(info.Status)
+	}
+	h.exporter.Export(*span)
+}
+
+func (h *traceHook) Finished(ctx context.Context, info CallInfo) {
+	h.end(ctx, info)
+}
+
+func (h *traceHook) Failed(ctx context.Context, info CallInfo) {
+	h.end(ctx, info)
+}
+
+// NewTraceHook opens a span per call, continuing the trace found in the
+// context, and propagates it downstream through the W3C traceparent header.
+func NewTraceHook(exporter SpanExporter) Hook {
+	return &traceHook{
+		exporter: exporter,
+	}
+}
@@ -0,0 +1,251 @@
+package endpoint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	transitCacheBase  = 44
+	transitCacheStart = 48
+	transitCacheSize  = transitCacheBase * transitCacheBase
+	transitMapMarker  = "^ "
+)
+
+// transitCodec handles application/transit+json by translating it from and to
+// plain JSON, so keywords and symbols become strings and sets and lists become arrays.
+type transitCodec struct{}
+
+func (c *transitCodec) Encode(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var plain any
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err = decoder.Decode(&plain)
+	if err != nil {
+		return err
+	}
+	encoded := transitEncode(plain)
+	switch encoded.(type) {
+	case []any, map[string]any:
+	default:
+		encoded = []any{"~#'", encoded}
+	}
+	return json.NewEncoder(w).Encode(encoded)
+}
+
+func transitEncode(value any) any {
+	switch v := value.(type) {
+	case string:
+		if strings.HasPrefix(v, "~") || strings.HasPrefix(v, "^") || strings.HasPrefix(v, "`") {
+			return "~" + v
+		}
+		return v
+	case []any:
+		result := make([]any, len(v))
+		for idx, item := range v {
+			result[idx] = transitEncode(item)
+		}
+		return result
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		result := make([]any, 0, len(v)*2+1)
+		result = append(result, transitMapMarker)
+		for _, key := range keys {
+			result = append(result, transitEncode(key), transitEncode(v[key]))
+		}
+		return result
+	}
+	return value
+}
+
+func (c *transitCodec) Decode(r io.Reader, v any) error {
+	var raw any
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	err := decoder.Decode(&raw)
+	if err != nil {
+		return errors.Wrap(err, "failed to read transit content")
+	}
+	plain, err := (&transitDecoder{}).decode(raw, false)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(plain)
+	if err != nil {
+		return err
+	}
+	return errors.Wrap(json.Unmarshal(data, v), "failed to unmarshal transit body")
+}
+
+type transitDecoder struct {
+	cache []string
+}
+
+func isTransitCacheRef(s string) bool {
+	return len(s) > 1 && s[0] == '^' && s != transitMapMarker
+}
+
+func transitCacheIndex(s string) int {
+	if len(s) == 2 {
+		return int(s[1]) - transitCacheStart
+	}
+	return (int(s[1])-transitCacheStart)*transitCacheBase + int(s[2]) - transitCacheStart
+}
+
+func isTransitCacheable(s string, asKey bool) bool {
+	if len(s) <= 3 {
+		return false
+	}
+	return asKey ||
+		strings.HasPrefix(s, "~:") ||
+		strings.HasPrefix(s, "~$") ||
+		strings.HasPrefix(s, "~#")
+}
+
+func (d *transitDecoder) resolve(s string, asKey bool) (string, error) {
+	if isTransitCacheRef(s) {
+		idx := transitCacheIndex(s)
+		if idx < 0 || idx >= len(d.cache) {
+			return "", errors.Errorf("endpoint: invalid transit cache reference - %s", s)
+		}
+		return d.cache[idx], nil
+	}
+	if isTransitCacheable(s, asKey) {
+		if len(d.cache) == transitCacheSize {
+			d.cache = d.cache[:0]
+		}
+		d.cache = append(d.cache, s)
+	}
+	return s, nil
+}
+
+func (d *transitDecoder) decode(value any, asKey bool) (any, error) {
+	switch v := value.(type) {
+	case string:
+		s, err := d.resolve(v, asKey)
+		if err != nil {
+			return nil, err
+		}
+		return transitScalar(s), nil
+	case []any:
+		return d.decodeArray(v)
+	case map[string]any:
+		if len(v) == 1 {
+			for key, tagged := range v {
+				if strings.HasPrefix(key, "~#") {
+					return d.decodeTagged(key[2:], tagged)
+				}
+			}
+		}
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			decodedKey, err := d.decode(key, true)
+			if err != nil {
+				return nil, err
+			}
+			decodedItem, err := d.decode(item, false)
+			if err != nil {
+				return nil, err
+			}
+			result[fmt.Sprint(decodedKey)] = decodedItem
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+func (d *transitDecoder) decodeArray(v []any) (any, error) {
+	if len(v) > 0 {
+		if head, ok := v[0].(string); ok {
+			if head == transitMapMarker {
+				return d.decodeMapEntries(v[1:])
+			}
+			resolved, err := d.resolve(head, false)
+			if err != nil {
+				return nil, err
+			}
+			if len(v) == 2 && strings.HasPrefix(resolved, "~#") {
+				return d.decodeTagged(resolved[2:], v[1])
+			}
+			result := make([]any, len(v))
+			result[0] = transitScalar(resolved)
+			for idx, item := range v[1:] {
+				result[idx+1], err = d.decode(item, false)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return result, nil
+		}
+	}
+	result := make([]any, len(v))
+	for idx, item := range v {
+		decoded, err := d.decode(item, false)
+		if err != nil {
+			return nil, err
+		}
+		result[idx] = decoded
+	}
+	return result, nil
+}
+
+func (d *transitDecoder) decodeMapEntries(entries []any) (any, error) {
+	if len(entries)%2 != 0 {
+		return nil, errors.New("endpoint: invalid transit map entries")
+	}
+	result := make(map[string]any, len(entries)/2)
+	for idx := 0; idx < len(entries); idx += 2 {
+		key, err := d.decode(entries[idx], true)
+		if err != nil {
+			return nil, err
+		}
+		item, err := d.decode(entries[idx+1], false)
+		if err != nil {
+			return nil, err
+		}
+		result[fmt.Sprint(key)] = item
+	}
+	return result, nil
+}
+
+func (d *transitDecoder) decodeTagged(tag string, value any) (any, error) {
+	if tag == "cmap" {
+		entries, ok := value.([]any)
+		if !ok {
+			return nil, errors.New("endpoint: invalid transit cmap")
+		}
+		return d.decodeMapEntries(entries)
+	}
+	return d.decode(value, false)
+}
+
+func transitScalar(s string) any {
+	if len(s) < 2 || s[0] != '~' {
+		return s
+	}
+	switch s[1] {
+	case '~', '^', '`':
+		return s[1:]
+	case '_':
+		return nil
+	case '?':
+		return s == "~?t"
+	case 'i', 'd', 'n', 'f':
+		return json.Number(s[2:])
+	}
+	return s[2:]
+}
@@ -0,0 +1,156 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+type withStatusErrorEndpointOption struct {
+	status int
+	err    error
+}
+
+func (o *withStatusErrorEndpointOption) apply(
+	ctx context.Context,
+	opts *endpointOptions,
+) error {
+	if opts.statusErrors == nil {
+		opts.statusErrors = map[int]error{}
+	}
+	opts.statusErrors[o.status] = o.err
+	return nil
+}
+
+func WithStatusError(status int, err error) EndpointOption {
+	return &withStatusErrorEndpointOption{
+		status: status,
+		err:    err,
+	}
+}
+
+func statusError(res *http.Response, statusErrors map[int]error) error {
+	err, ok := statusErrors[res.StatusCode]
+	if !ok {
+		return nil
+	}
+	_ = res.Body.Close()
+	return errors.Wrapf(err, "endpoint: response status %d", res.StatusCode)
+}
+
+// responseError reports the statuses the error analyzers let through, as 404
+// with the default one, since no value can be decoded from them.
+func responseError(res Response) error {
+	_, body, err := res.RawBody()
+	if err != nil {
+		return err
+	}
+	httpRes := &http.Response{
+		StatusCode: res.Status(),
+		Header:     res.Headers(),
+	}
+	if r, ok := res.(*response); ok {
+		httpRes.Request = r.Response.Request
+	}
+	return errors.WithStack(NewHTTPError(httpRes, body))
+}
+
+func decodeAs[T any](res Response, err error) (T, Response, error) {
+	var result T
+	if err != nil {
+		return result, res, err
+	}
+	defer res.Close()
+
+	status := res.Status()
+	if status == http.StatusNoContent {
+		return result, res, nil
+	}
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return result, res, responseError(res)
+	}
+
+	err = res.Unmarshal(&result)
+	if err != nil {
+		return result, res, err
+	}
+	return result, res, nil
+}
+
+func GetAs[T any](
+	ctx context.Context,
+	end Endpoint,
+	options ...EndpointOption,
+) (T, Response, error) {
+	return decodeAs[T](end.Get(ctx, options...))
+}
+
+func HeadAs[T any](
+	ctx context.Context,
+	end Endpoint,
+	options ...EndpointOption,
+) (T, Response, error) {
+	var result T
+	res, err := end.Head(ctx, options...)
+	if err != nil {
+		return result, res, err
+	}
+	return result, res, res.Close()
+}
+
+func PostAs[T any](
+	ctx context.Context,
+	end Endpoint,
+	options ...EndpointOption,
+) (T, Response, error) {
+	return decodeAs[T](end.Post(ctx, options...))
+}
+
+func PutAs[T any](
+	ctx context.Context,
+	end Endpoint,
+	options ...EndpointOption,
+) (T, Response, error) {
+	return decodeAs[T](end.Put(ctx, options...))
+}
+
+func PatchAs[T any](
+	ctx context.Context,
+	end Endpoint,
+	options ...EndpointOption,
+) (T, Response, error) {
+	return decodeAs[T](end.Patch(ctx, options...))
+}
+
+func DeleteAs[T any](
+	ctx context.Context,
+	end Endpoint,
+	options ...EndpointOption,
+) (T, Response, error) {
+	return decodeAs[T](end.Delete(ctx, options...))
+}
+
+func ConnectAs[T any](
+	ctx context.Context,
+	end Endpoint,
+	options ...EndpointOption,
+) (T, Response, error) {
+	return decodeAs[T](end.Connect(ctx, options...))
+}
+
+func OptionsAs[T any](
+	ctx context.Context,
+	end Endpoint,
+	options ...EndpointOption,
+) (T, Response, error) {
+	return decodeAs[T](end.Options(ctx, options...))
+}
+
+func TraceAs[T any](
+	ctx context.Context,
+	end Endpoint,
+	options ...EndpointOption,
+) (T, Response, error) {
+	return decodeAs[T](end.Trace(ctx, options...))
+}
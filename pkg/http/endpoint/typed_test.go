@@ -0,0 +1,108 @@
+package endpoint
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/http/httptest"
+)
+
+var errSomeTypeNotFound = errors.New("some type not found")
+
+func Test_GetAs(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as/{paramA}",
+		server.Requester(),
+		WithStatusError(http.StatusNotFound, errSomeTypeNotFound),
+	)
+
+	expected := someType{
+		ID: 42,
+	}
+
+	server.
+		Get("/api/v1/as/some_value").
+		ReturnEDN(
+			200,
+			expected,
+			http.Header{},
+		).
+		Return(
+			404,
+			[]byte(`Not Found`),
+			http.Header{},
+		)
+
+	result, response, err := GetAs[someType](context.Background(), end,
+		WithParam("paramA", "some_value"),
+	)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	assert.Equal(t, 200, response.Status())
+	assert.Equal(t, expected, result)
+
+	result, _, err = GetAs[someType](context.Background(), end,
+		WithParam("paramA", "some_value"),
+	)
+	assert.True(t, errors.Is(err, errSomeTypeNotFound))
+	assert.Equal(t, someType{}, result)
+}
+
+func Test_DeleteAs_NoContent(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as/{paramA}",
+		server.Requester(),
+	)
+
+	server.
+		Delete("/api/v1/as/some_value").
+		Return(
+			204,
+			nil,
+			http.Header{},
+		)
+
+	result, response, err := DeleteAs[*someType](context.Background(), end,
+		WithParam("paramA", "some_value"),
+	)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	assert.Equal(t, 204, response.Status())
+	assert.Nil(t, result)
+}
+
+func Test_GetAs_NotFound(t *testing.T) {
+	server := httptest.New(t)
+	end := NewEndpoint(
+		server.BaseURL(),
+		"/api/v1/as/{paramA}",
+		server.Requester(),
+	)
+
+	server.
+		Get("/api/v1/as/some_value").
+		Return(
+			404,
+			[]byte(`Not Found`),
+			http.Header{},
+		)
+
+	result, response, err := GetAs[someType](context.Background(), end,
+		WithParam("paramA", "some_value"),
+	)
+	assert.True(t, IsStatus(err, http.StatusNotFound))
+	assert.Equal(t, 404, response.Status())
+	assert.Equal(t, someType{}, result)
+}
@@ -0,0 +1,188 @@
+package requester
+
+import (
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitorsss/go-helpers/pkg/logs"
+)
+
+var ErrBulkheadFull = errors.New("requester: bulkhead full")
+
+type bulkheadOptions struct {
+	keyFn        KeyFn
+	maxQueue     int
+	queueTimeout time.Duration
+}
+
+type BulkheadOption func(opts *bulkheadOptions)
+
+func WithBulkheadKey(keyFn KeyFn) BulkheadOption {
+	return func(opts *bulkheadOptions) {
+		opts.keyFn = keyFn
+	}
+}
+
+// WithBulkheadQueue lets up to size callers wait for a slot for at most
+// timeout, by default callers are rejected as soon as every slot is taken.
+func WithBulkheadQueue(size int, timeout time.Duration) BulkheadOption {
+	return func(opts *bulkheadOptions) {
+		opts.maxQueue = size
+		opts.queueTimeout = timeout
+	}
+}
+
+type bulkheadCompartment struct {
+	slots   chan struct{}
+	waiting int
+	users   int
+}
+
+type bulkheadRequester struct {
+	requester     Requester
+	maxConcurrent int
+	opts          *bulkheadOptions
+	lock          sync.Mutex
+	compartments  map[string]*bulkheadCompartment
+}
+
+func (c *bulkheadRequester) enter(key string) *bulkheadCompartment {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	compartment, ok := c.compartments[key]
+	if !ok {
+		compartment = &bulkheadCompartment{
+			slots: make(chan struct{}, c.maxConcurrent),
+		}
+		c.compartments[key] = compartment
+	}
+	compartment.users++
+	return compartment
+}
+
+func (c *bulkheadRequester) leave(key string, compartment *bulkheadCompartment) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	compartment.users--
+	if compartment.users == 0 {
+		delete(c.compartments, key)
+	}
+}
+
+func (c *bulkheadRequester) queue(compartment *bulkheadCompartment) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if compartment.waiting >= c.opts.maxQueue {
+		return false
+	}
+	compartment.waiting++
+	return true
+}
+
+func (c *bulkheadRequester) dequeue(compartment *bulkheadCompartment) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	compartment.waiting--
+}
+
+func (c *bulkheadRequester) acquire(req *http.Request, compartment *bulkheadCompartment) error {
+	select {
+	case compartment.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	if !c.queue(compartment) {
+		return errors.Wrap(ErrBulkheadFull, "queue is full")
+	}
+	defer c.dequeue(compartment)
+
+	var timeout <-chan time.Time
+	if c.opts.queueTimeout > 0 {
+		timer := time.NewTimer(c.opts.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case compartment.slots <- struct{}{}:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	case <-timeout:
+		return errors.Wrap(ErrBulkheadFull, "queue timeout")
+	}
+}
+
+// bulkheadBody holds the slot until the response body is closed.
+type bulkheadBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *bulkheadBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+func (c *bulkheadRequester) Do(req *http.Request) (*http.Response, error) {
+	key := c.opts.keyFn(req)
+	compartment := c.enter(key)
+	err := c.acquire(req, compartment)
+	if err != nil {
+		c.leave(key, compartment)
+		return nil, err
+	}
+	release := func() {
+		<-compartment.slots
+		c.leave(key, compartment)
+	}
+
+	res, err := c.requester.Do(req)
+	if err != nil || res.Body == nil {
+		release()
+		return res, err
+	}
+	res.Body = &bulkheadBody{
+		ReadCloser: res.Body,
+		release:    release,
+	}
+	return res, nil
+}
+
+// NewBulkheadRequester caps the in flight requests of each key at maxConcurrent,
+// a request stays in flight until its response body is closed.
+func NewBulkheadRequester(
+	requester Requester,
+	maxConcurrent int,
+	options ...BulkheadOption,
+) Requester {
+	if maxConcurrent < 1 {
+		err := errors.New("requester: bulkhead max concurrent must be at least 1")
+		logs.Logger.Error().Err(err).Send()
+		panic(err)
+	}
+	opts := &bulkheadOptions{
+		keyFn: func(req *http.Request) string {
+			return ""
+		},
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	return &bulkheadRequester{
+		requester:     requester,
+		maxConcurrent: maxConcurrent,
+		opts:          opts,
+		compartments:  map[string]*bulkheadCompartment{},
+	}
+}
+
+func BulkheadMiddleware(maxConcurrent int, options ...BulkheadOption) Middleware {
+	return func(requester Requester) Requester {
+		return NewBulkheadRequester(requester, maxConcurrent, options...)
+	}
+}
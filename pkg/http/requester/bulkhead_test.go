@@ -0,0 +1,98 @@
+package requester
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+)
+
+func Test_BulkheadRequester(t *testing.T) {
+	requester := NewBulkheadRequester(
+		okRequester(),
+		1,
+		WithBulkheadKey(HostKey),
+		WithBulkheadQueue(1, 20*time.Millisecond),
+	)
+	do := func(url string) (*http.Response, error) {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		return requester.Do(req)
+	}
+
+	first, err := do("http://a.example")
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	other, err := do("http://b.example")
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	_ = other.Body.Close()
+
+	// the only slot is held until the body is closed
+	_, err = do("http://a.example")
+	assert.ErrorIs(t, err, ErrBulkheadFull)
+
+	queued := make(chan error, 1)
+	go func() {
+		res, err := do("http://a.example")
+		if err == nil {
+			_ = res.Body.Close()
+		}
+		queued <- err
+	}()
+	assert.Eventually(t, func() bool {
+		bulkhead := requester.(*bulkheadRequester)
+		bulkhead.lock.Lock()
+		defer bulkhead.lock.Unlock()
+		return bulkhead.compartments["a.example"].waiting == 1
+	}, time.Second, time.Millisecond)
+
+	_, err = do("http://a.example")
+	assert.ErrorIs(t, err, ErrBulkheadFull)
+
+	_ = first.Body.Close()
+	assertutil.Error(t, nil, <-queued)
+	assert.Empty(t, requester.(*bulkheadRequester).compartments)
+}
+
+func Test_BulkheadRequester_Canceled(t *testing.T) {
+	release := make(chan struct{})
+	requester := NewBulkheadRequester(
+		RequesterFunc(func(req *http.Request) (*http.Response, error) {
+			<-release
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}),
+		1,
+		WithBulkheadQueue(1, time.Minute),
+	)
+	go func() {
+		req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		res, err := requester.Do(req)
+		if err == nil {
+			_ = res.Body.Close()
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+	_, err := requester.Do(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	close(release)
+}
+
+func Test_NewBulkheadRequester_InvalidMaxConcurrent(t *testing.T) {
+	assert.Panics(t, func() {
+		NewBulkheadRequester(okRequester(), 0)
+	})
+}
@@ -0,0 +1,141 @@
+package requester
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitorsss/go-helpers/pkg/files"
+)
+
+type CachedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	VaryHeader http.Header `json:"vary_header"`
+	// Credentials is a hash of the credential headers the response was fetched with.
+	Credentials  string    `json:"credentials,omitempty"`
+	RequestTime  time.Time `json:"request_time"`
+	ResponseTime time.Time `json:"response_time"`
+}
+
+// CacheStore Get returns nil without error when the key is not cached.
+type CacheStore interface {
+	Get(key string) (*CachedResponse, error)
+	Set(key string, res *CachedResponse) error
+	Delete(key string) error
+}
+
+type memoryCacheEntry struct {
+	key string
+	res *CachedResponse
+}
+
+type memoryCacheStore struct {
+	maxEntries int
+	lock       sync.Mutex
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+func (s *memoryCacheStore) Get(key string) (*CachedResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*memoryCacheEntry).res, nil
+}
+
+func (s *memoryCacheStore) Set(key string, res *CachedResponse) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if element, ok := s.entries[key]; ok {
+		element.Value.(*memoryCacheEntry).res = res
+		s.order.MoveToFront(element)
+		return nil
+	}
+	s.entries[key] = s.order.PushFront(&memoryCacheEntry{
+		key: key,
+		res: res,
+	})
+	for s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+	return nil
+}
+
+func (s *memoryCacheStore) Delete(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if element, ok := s.entries[key]; ok {
+		s.order.Remove(element)
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// NewMemoryCacheStore keeps at most maxEntries responses, evicting the least
+// recently used one, zero means unbounded.
+func NewMemoryCacheStore(maxEntries int) CacheStore {
+	return &memoryCacheStore{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+	}
+}
+
+type diskCacheStore struct {
+	dir  string
+	lock sync.RWMutex
+}
+
+func (s *diskCacheStore) filePath(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(hash[:])+".json")
+}
+
+func (s *diskCacheStore) Get(key string) (*CachedResponse, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	content, err := files.ReadJSONFile[CachedResponse](s.filePath(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read cached response")
+	}
+	return &content.Content, nil
+}
+
+func (s *diskCacheStore) Set(key string, res *CachedResponse) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return errors.Wrap(files.WriteJSONFile(s.filePath(key), res), "failed to write cached response")
+}
+
+func (s *diskCacheStore) Delete(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	err := os.Remove(s.filePath(key))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return errors.Wrap(err, "failed to remove cached response")
+	}
+	return nil
+}
+
+func NewDiskCacheStore(dir string) CacheStore {
+	return &diskCacheStore{
+		dir: dir,
+	}
+}
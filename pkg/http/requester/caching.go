@@ -0,0 +1,372 @@
+package requester
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type cacheControl map[string]string
+
+func parseCacheControl(header http.Header) cacheControl {
+	result := cacheControl{}
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name == "" {
+				continue
+			}
+			result[strings.ToLower(name)] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+	return result
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	value, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+var cacheableStatuses = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusGone:                 true,
+}
+
+func cacheKey(method string, req *http.Request) string {
+	return method + " " + req.URL.String()
+}
+
+func varyFields(header http.Header) []string {
+	fields := []string{}
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			field = strings.TrimSpace(field)
+			if field != "" {
+				fields = append(fields, http.CanonicalHeaderKey(field))
+			}
+		}
+	}
+	return fields
+}
+
+// credentialsHash identifies the caller credentials without storing them, so
+// a response is only served to requests sent with the same ones.
+func credentialsHash(req *http.Request) string {
+	hasher := sha256.New()
+	found := false
+	for _, header := range credentialHeaders {
+		for _, value := range req.Header.Values(header) {
+			found = true
+			fmt.Fprintf(hasher, "%s: %s\n", header, value)
+		}
+	}
+	if !found {
+		return ""
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+func varyMatches(cached *CachedResponse, req *http.Request) bool {
+	if cached.Credentials != credentialsHash(req) {
+		return false
+	}
+	for field, values := range cached.VaryHeader {
+		if !slices.Equal(values, req.Header.Values(field)) {
+			return false
+		}
+	}
+	return true
+}
+
+func freshnessLifetime(cached *CachedResponse) time.Duration {
+	cc := parseCacheControl(cached.Header)
+	if cc.has("no-cache") {
+		return 0
+	}
+	if maxAge, ok := cc.seconds("max-age"); ok {
+		return maxAge
+	}
+	if expires := cached.Header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			return 0
+		}
+		date, err := http.ParseTime(cached.Header.Get("Date"))
+		if err != nil {
+			date = cached.ResponseTime
+		}
+		return expiresAt.Sub(date)
+	}
+	return 0
+}
+
+func currentAge(cached *CachedResponse, now time.Time) time.Duration {
+	age := now.Sub(cached.ResponseTime)
+	if seconds, err := strconv.ParseInt(cached.Header.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		age += time.Duration(seconds) * time.Second
+	}
+	if age < 0 {
+		return 0
+	}
+	return age
+}
+
+func isFresh(cached *CachedResponse, requestCC cacheControl, now time.Time) bool {
+	lifetime := freshnessLifetime(cached)
+	age := currentAge(cached, now)
+	if maxAge, ok := requestCC.seconds("max-age"); ok && age > maxAge {
+		return false
+	}
+	if minFresh, ok := requestCC.seconds("min-fresh"); ok && lifetime-age < minFresh {
+		return false
+	}
+	if lifetime > age {
+		return true
+	}
+	if !requestCC.has("max-stale") || parseCacheControl(cached.Header).has("must-revalidate") {
+		return false
+	}
+	maxStale, ok := requestCC.seconds("max-stale")
+	return !ok || age-lifetime <= maxStale
+}
+
+func isStorable(res *http.Response) bool {
+	cc := parseCacheControl(res.Header)
+	if !cacheableStatuses[res.StatusCode] || cc.has("no-store") || res.Header.Get("Vary") == "*" {
+		return false
+	}
+	return cc.has("max-age") ||
+		cc.has("no-cache") ||
+		res.Header.Get("Expires") != "" ||
+		res.Header.Get("ETag") != "" ||
+		res.Header.Get("Last-Modified") != ""
+}
+
+type cachingOptions struct {
+	now func() time.Time
+}
+
+type CachingOption func(opts *cachingOptions)
+
+func WithCacheClock(now func() time.Time) CachingOption {
+	return func(opts *cachingOptions) {
+		opts.now = now
+	}
+}
+
+type cachingRequester struct {
+	requester Requester
+	store     CacheStore
+	opts      *cachingOptions
+}
+
+func (c *cachingRequester) cachedResponse(
+	req *http.Request,
+	cached *CachedResponse,
+	now time.Time,
+) *http.Response {
+	header := cached.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set("Age", strconv.FormatInt(int64(currentAge(cached, now)/time.Second), 10))
+	return &http.Response{
+		Status:        http.StatusText(cached.StatusCode),
+		StatusCode:    cached.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}
+}
+
+func (c *cachingRequester) invalidate(req *http.Request) error {
+	for _, method := range []string{http.MethodGet, http.MethodHead} {
+		err := c.store.Delete(cacheKey(method, req))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cachingRequester) storeResponse(
+	key string,
+	req *http.Request,
+	res *http.Response,
+	requestTime time.Time,
+	responseTime time.Time,
+) (*http.Response, error) {
+	if !isStorable(res) {
+		return res, nil
+	}
+	body, err := readResponseBody(res)
+	if err != nil {
+		return nil, err
+	}
+	vary := http.Header{}
+	for _, field := range varyFields(res.Header) {
+		vary[field] = req.Header.Values(field)
+	}
+	err = c.store.Set(key, &CachedResponse{
+		StatusCode:   res.StatusCode,
+		Header:       res.Header.Clone(),
+		Body:         body,
+		VaryHeader:   vary,
+		Credentials:  credentialsHash(req),
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *cachingRequester) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		res, err := c.requester.Do(req)
+		if err == nil && req.Method != http.MethodOptions && req.Method != http.MethodTrace &&
+			res.StatusCode < http.StatusBadRequest {
+			// unsafe methods invalidate the stored responses for the target uri
+			err = c.invalidate(req)
+			if err != nil {
+				_ = res.Body.Close()
+				return nil, err
+			}
+		}
+		return res, err
+	}
+
+	requestCC := parseCacheControl(req.Header)
+	if requestCC.has("no-store") {
+		return c.requester.Do(req)
+	}
+	key := cacheKey(req.Method, req)
+	cached, err := c.store.Get(key)
+	if err != nil {
+		// an unreadable entry, as a corrupt disk file, is fetched again
+		cached = nil
+	}
+	if cached != nil && !varyMatches(cached, req) {
+		cached = nil
+	}
+
+	now := c.opts.now()
+	if cached != nil && !requestCC.has("no-cache") && isFresh(cached, requestCC, now) {
+		return c.cachedResponse(req, cached, now), nil
+	}
+	if requestCC.has("only-if-cached") {
+		return &http.Response{
+			Status:     http.StatusText(http.StatusGatewayTimeout),
+			StatusCode: http.StatusGatewayTimeout,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+
+	outgoing := req
+	revalidating := false
+	if cached != nil &&
+		req.Header.Get("If-None-Match") == "" &&
+		req.Header.Get("If-Modified-Since") == "" {
+		etag := cached.Header.Get("ETag")
+		lastModified := cached.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			outgoing = req.Clone(req.Context())
+			if etag != "" {
+				outgoing.Header.Set("If-None-Match", etag)
+			}
+			if lastModified != "" {
+				outgoing.Header.Set("If-Modified-Since", lastModified)
+			}
+			revalidating = true
+		}
+	}
+
+	res, err := c.requester.Do(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	responseTime := c.opts.now()
+
+	if revalidating && res.StatusCode == http.StatusNotModified {
+		_, _ = io.Copy(io.Discard, res.Body)
+		_ = res.Body.Close()
+		updated := *cached
+		updated.Header = cached.Header.Clone()
+		for field, values := range res.Header {
+			if field == "Content-Length" {
+				continue
+			}
+			updated.Header[field] = values
+		}
+		updated.Header.Del("Age")
+		updated.RequestTime = now
+		updated.ResponseTime = responseTime
+		err = c.store.Set(key, &updated)
+		if err != nil {
+			return nil, err
+		}
+		return c.cachedResponse(req, &updated, responseTime), nil
+	}
+	return c.storeResponse(key, req, res, now, responseTime)
+}
+
+// NewCachingRequester implements a private RFC 9111 cache for GET and HEAD
+// requests, responses without explicit freshness are always revalidated.
+func NewCachingRequester(
+	requester Requester,
+	store CacheStore,
+	options ...CachingOption,
+) Requester {
+	opts := &cachingOptions{
+		now: time.Now,
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	return &cachingRequester{
+		requester: requester,
+		store:     store,
+		opts:      opts,
+	}
+}
+
+func CachingMiddleware(store CacheStore, options ...CachingOption) Middleware {
+	return func(requester Requester) Requester {
+		return NewCachingRequester(requester, store, options...)
+	}
+}
@@ -0,0 +1,247 @@
+package requester
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+)
+
+type cachingServer struct {
+	calls    int
+	requests []*http.Request
+	handler  func(req *http.Request) *http.Response
+}
+
+func (s *cachingServer) Do(req *http.Request) (*http.Response, error) {
+	s.calls++
+	s.requests = append(s.requests, req)
+	return s.handler(req), nil
+}
+
+func cachingResponse(status int, body string, header http.Header) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+}
+
+func doCached(t *testing.T, requester Requester, header http.Header) (int, string) {
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/countries", nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	res, err := requester.Do(req)
+	if !assertutil.Error(t, nil, err) {
+		return 0, ""
+	}
+	body, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	return res.StatusCode, string(body)
+}
+
+func Test_CachingRequester(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		return now
+	}
+
+	t.Run("serves fresh responses from the cache", func(t *testing.T) {
+		server := &cachingServer{
+			handler: func(req *http.Request) *http.Response {
+				return cachingResponse(200, "v1", http.Header{"Cache-Control": []string{"max-age=60"}})
+			},
+		}
+		requester := NewCachingRequester(server, NewMemoryCacheStore(10), WithCacheClock(clock))
+		for i := 0; i < 3; i++ {
+			status, body := doCached(t, requester, nil)
+			assert.Equal(t, 200, status)
+			assert.Equal(t, "v1", body)
+		}
+		assert.Equal(t, 1, server.calls)
+
+		status, body := doCached(t, requester, http.Header{"Cache-Control": []string{"no-cache"}})
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "v1", body)
+		assert.Equal(t, 2, server.calls)
+	})
+
+	t.Run("revalidates stale responses with etags", func(t *testing.T) {
+		current := now
+		server := &cachingServer{
+			handler: func(req *http.Request) *http.Response {
+				if req.Header.Get("If-None-Match") == `"v1"` {
+					return cachingResponse(304, "", http.Header{"Cache-Control": []string{"max-age=10"}})
+				}
+				return cachingResponse(200, "v1", http.Header{
+					"Etag":          []string{`"v1"`},
+					"Cache-Control": []string{"max-age=10"},
+				})
+			},
+		}
+		requester := NewCachingRequester(server, NewMemoryCacheStore(10), WithCacheClock(func() time.Time {
+			return current
+		}))
+		doCached(t, requester, nil)
+		current = current.Add(20 * time.Second)
+		status, body := doCached(t, requester, nil)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "v1", body)
+		assert.Equal(t, 2, server.calls)
+		assert.Equal(t, `"v1"`, server.requests[1].Header.Get("If-None-Match"))
+
+		current = current.Add(5 * time.Second)
+		doCached(t, requester, nil)
+		assert.Equal(t, 2, server.calls)
+	})
+
+	t.Run("does not store no-store responses", func(t *testing.T) {
+		server := &cachingServer{
+			handler: func(req *http.Request) *http.Response {
+				return cachingResponse(200, "v1", http.Header{"Cache-Control": []string{"no-store, max-age=60"}})
+			},
+		}
+		requester := NewCachingRequester(server, NewMemoryCacheStore(10), WithCacheClock(clock))
+		doCached(t, requester, nil)
+		doCached(t, requester, nil)
+		assert.Equal(t, 2, server.calls)
+	})
+
+	t.Run("unsafe methods invalidate the cache", func(t *testing.T) {
+		server := &cachingServer{
+			handler: func(req *http.Request) *http.Response {
+				return cachingResponse(200, "v1", http.Header{"Cache-Control": []string{"max-age=60"}})
+			},
+		}
+		requester := NewCachingRequester(server, NewMemoryCacheStore(10), WithCacheClock(clock))
+		doCached(t, requester, nil)
+		req, _ := http.NewRequest(http.MethodPut, "http://example.com/countries", strings.NewReader("{}"))
+		_, err := requester.Do(req)
+		assertutil.Error(t, nil, err)
+		doCached(t, requester, nil)
+		assert.Equal(t, 3, server.calls)
+	})
+
+	t.Run("vary headers select the stored response", func(t *testing.T) {
+		server := &cachingServer{
+			handler: func(req *http.Request) *http.Response {
+				return cachingResponse(200, req.Header.Get("Accept-Language"), http.Header{
+					"Cache-Control": []string{"max-age=60"},
+					"Vary":          []string{"Accept-Language"},
+				})
+			},
+		}
+		requester := NewCachingRequester(server, NewMemoryCacheStore(10), WithCacheClock(clock))
+		_, body := doCached(t, requester, http.Header{"Accept-Language": []string{"en"}})
+		assert.Equal(t, "en", body)
+		_, body = doCached(t, requester, http.Header{"Accept-Language": []string{"pt"}})
+		assert.Equal(t, "pt", body)
+		assert.Equal(t, 2, server.calls)
+	})
+
+	t.Run("credentials select the stored response", func(t *testing.T) {
+		server := &cachingServer{
+			handler: func(req *http.Request) *http.Response {
+				return cachingResponse(200, req.Header.Get("Authorization"), http.Header{
+					"Cache-Control": []string{"max-age=60"},
+				})
+			},
+		}
+		requester := NewCachingRequester(server, NewMemoryCacheStore(10), WithCacheClock(clock))
+		_, body := doCached(t, requester, http.Header{"Authorization": []string{"Bearer a"}})
+		assert.Equal(t, "Bearer a", body)
+		_, body = doCached(t, requester, http.Header{"Authorization": []string{"Bearer b"}})
+		assert.Equal(t, "Bearer b", body)
+		_, body = doCached(t, requester, nil)
+		assert.Equal(t, "", body)
+		assert.Equal(t, 3, server.calls)
+	})
+
+	t.Run("unreadable entries are a cache miss", func(t *testing.T) {
+		server := &cachingServer{
+			handler: func(req *http.Request) *http.Response {
+				return cachingResponse(200, "v1", http.Header{"Cache-Control": []string{"max-age=60"}})
+			},
+		}
+		requester := NewCachingRequester(server, failingCacheStore{}, WithCacheClock(clock))
+		status, body := doCached(t, requester, nil)
+		assert.Equal(t, 200, status)
+		assert.Equal(t, "v1", body)
+	})
+}
+
+type failingCacheStore struct{}
+
+func (failingCacheStore) Get(key string) (*CachedResponse, error) {
+	return nil, errors.New("corrupt entry")
+}
+
+func (failingCacheStore) Set(key string, res *CachedResponse) error {
+	return nil
+}
+
+func (failingCacheStore) Delete(key string) error {
+	return nil
+}
+
+func Test_CacheStores(t *testing.T) {
+	tests := []struct {
+		name  string
+		store CacheStore
+	}{
+		{
+			name:  "memory",
+			store: NewMemoryCacheStore(2),
+		},
+		{
+			name:  "disk",
+			store: NewDiskCacheStore(t.TempDir()),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cached, err := tt.store.Get("a")
+			assertutil.Error(t, nil, err)
+			assert.Nil(t, cached)
+
+			entry := &CachedResponse{
+				StatusCode: 200,
+				Header:     http.Header{"Etag": []string{`"a"`}},
+				Body:       []byte("body"),
+			}
+			assertutil.Error(t, nil, tt.store.Set("a", entry))
+			cached, err = tt.store.Get("a")
+			if !assertutil.Error(t, nil, err) || !assert.NotNil(t, cached) {
+				return
+			}
+			assert.Equal(t, entry.Body, cached.Body)
+			assert.Equal(t, entry.Header, cached.Header)
+
+			assertutil.Error(t, nil, tt.store.Delete("a"))
+			cached, err = tt.store.Get("a")
+			assertutil.Error(t, nil, err)
+			assert.Nil(t, cached)
+		})
+	}
+}
+
+func Test_MemoryCacheStore_Eviction(t *testing.T) {
+	store := NewMemoryCacheStore(2)
+	_ = store.Set("a", &CachedResponse{})
+	_ = store.Set("b", &CachedResponse{})
+	_, _ = store.Get("a")
+	_ = store.Set("c", &CachedResponse{})
+
+	a, _ := store.Get("a")
+	b, _ := store.Get("b")
+	c, _ := store.Get("c")
+	assert.NotNil(t, a)
+	assert.Nil(t, b)
+	assert.NotNil(t, c)
+}
@@ -0,0 +1,329 @@
+package requester
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/vitorsss/go-helpers/pkg/files"
+)
+
+var ErrCassetteInteractionNotFound = errors.New("requester: cassette interaction not found")
+
+type CassetteMode int
+
+const (
+	CassetteReplay CassetteMode = iota
+	CassetteRecord
+	CassettePassthrough
+)
+
+type CassetteMatch int
+
+const (
+	MatchMethod CassetteMatch = 1 << iota
+	MatchPath
+	MatchQuery
+	MatchBody
+)
+
+const base64BodyEncoding = "base64"
+
+type CassetteRequest struct {
+	Method       string      `json:"method" edn:"method"`
+	URL          string      `json:"url" edn:"url"`
+	Header       http.Header `json:"header" edn:"header"`
+	Body         string      `json:"body" edn:"body"`
+	BodyEncoding string      `json:"body_encoding,omitempty" edn:"body-encoding,omitempty"`
+}
+
+type CassetteResponse struct {
+	Status       int         `json:"status" edn:"status"`
+	Header       http.Header `json:"header" edn:"header"`
+	Body         string      `json:"body" edn:"body"`
+	BodyEncoding string      `json:"body_encoding,omitempty" edn:"body-encoding,omitempty"`
+}
+
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request" edn:"request"`
+	Response CassetteResponse `json:"response" edn:"response"`
+}
+
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), base64BodyEncoding
+}
+
+func decodeBody(body string, encoding string) ([]byte, error) {
+	if encoding == base64BodyEncoding {
+		return base64.StdEncoding.DecodeString(body)
+	}
+	return []byte(body), nil
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get request body")
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	content, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read request body")
+	}
+	req.Body = io.NopCloser(bytes.NewReader(content))
+	return content, nil
+}
+
+func readResponseBody(res *http.Response) ([]byte, error) {
+	if res.Body == nil {
+		return nil, nil
+	}
+	content, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response body")
+	}
+	res.Body = io.NopCloser(bytes.NewReader(content))
+	return content, nil
+}
+
+type cassetteOptions struct {
+	requester Requester
+	match     CassetteMatch
+	redactor  *Redactor
+}
+
+type CassetteOption func(opts *cassetteOptions)
+
+func WithCassetteRequester(requester Requester) CassetteOption {
+	return func(opts *cassetteOptions) {
+		opts.requester = requester
+	}
+}
+
+func WithCassetteMatch(match CassetteMatch) CassetteOption {
+	return func(opts *cassetteOptions) {
+		opts.match = match
+	}
+}
+
+func WithCassetteRedactor(redactor *Redactor) CassetteOption {
+	return func(opts *cassetteOptions) {
+		opts.redactor = redactor
+	}
+}
+
+type CassetteRequester struct {
+	path         string
+	mode         CassetteMode
+	opts         *cassetteOptions
+	lock         sync.Mutex
+	interactions []CassetteInteraction
+	used         []bool
+}
+
+func isEDNPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".edn")
+}
+
+func (c *CassetteRequester) load() error {
+	var content *files.FileContent[[]CassetteInteraction]
+	var err error
+	if isEDNPath(c.path) {
+		content, err = files.ReadEDNFile[[]CassetteInteraction](c.path)
+	} else {
+		content, err = files.ReadJSONFile[[]CassetteInteraction](c.path)
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to load cassette")
+	}
+	c.interactions = content.Content
+	c.used = make([]bool, len(c.interactions))
+	return nil
+}
+
+func (c *CassetteRequester) save() error {
+	var err error
+	if isEDNPath(c.path) {
+		err = files.WriteEDNFile(c.path, c.interactions)
+	} else {
+		err = files.WriteJSONFile(c.path, c.interactions)
+	}
+	return errors.Wrap(err, "failed to save cassette")
+}
+
+func (c *CassetteRequester) Interactions() []CassetteInteraction {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]CassetteInteraction{}, c.interactions...)
+}
+
+func (c *CassetteRequester) Do(req *http.Request) (*http.Response, error) {
+	switch c.mode {
+	case CassettePassthrough:
+		return c.opts.requester.Do(req)
+	case CassetteRecord:
+		return c.record(req)
+	}
+	return c.replay(req)
+}
+
+func (c *CassetteRequester) record(req *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.opts.requester.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := readResponseBody(res)
+	if err != nil {
+		return nil, err
+	}
+
+	redactor := c.opts.redactor
+	interaction := CassetteInteraction{
+		Request: CassetteRequest{
+			Method: req.Method,
+			URL:    redactor.URL(req.URL),
+			Header: redactor.Header(req.Header),
+		},
+		Response: CassetteResponse{
+			Status: res.StatusCode,
+			Header: redactor.Header(res.Header),
+		},
+	}
+	interaction.Request.Body, interaction.Request.BodyEncoding = encodeBody(
+		redactor.Body(req.Header.Get("Content-Type"), requestBody),
+	)
+	interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(
+		redactor.Body(res.Header.Get("Content-Type"), responseBody),
+	)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.interactions = append(c.interactions, interaction)
+	c.used = append(c.used, true)
+	err = c.save()
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *CassetteRequester) matches(
+	interaction CassetteInteraction,
+	req *http.Request,
+	query url.Values,
+	body []byte,
+) bool {
+	recorded, err := url.Parse(interaction.Request.URL)
+	if err != nil {
+		return false
+	}
+	if c.opts.match&MatchMethod != 0 && interaction.Request.Method != req.Method {
+		return false
+	}
+	if c.opts.match&MatchPath != 0 && recorded.Path != req.URL.Path {
+		return false
+	}
+	if c.opts.match&MatchQuery != 0 && !reflect.DeepEqual(recorded.Query(), query) {
+		return false
+	}
+	if c.opts.match&MatchBody != 0 {
+		recordedBody, err := decodeBody(interaction.Request.Body, interaction.Request.BodyEncoding)
+		if err != nil || !bytes.Equal(recordedBody, body) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *CassetteRequester) replay(req *http.Request) (*http.Response, error) {
+	redactor := c.opts.redactor
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	body = redactor.Body(req.Header.Get("Content-Type"), body)
+	if body == nil {
+		body = []byte{}
+	}
+	redactedURL, err := url.Parse(redactor.URL(req.URL))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse request url")
+	}
+	query := redactedURL.Query()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for idx, interaction := range c.interactions {
+		if c.used[idx] || !c.matches(interaction, req, query, body) {
+			continue
+		}
+		c.used[idx] = true
+		responseBody, err := decodeBody(interaction.Response.Body, interaction.Response.BodyEncoding)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to decode cassette response body")
+		}
+		return &http.Response{
+			Status:        http.StatusText(interaction.Response.Status),
+			StatusCode:    interaction.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(responseBody)),
+			ContentLength: int64(len(responseBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, errors.Wrapf(ErrCassetteInteractionNotFound, "%s %s", req.Method, redactedURL.String())
+}
+
+func NewCassetteRequester(
+	path string,
+	mode CassetteMode,
+	options ...CassetteOption,
+) (*CassetteRequester, error) {
+	opts := &cassetteOptions{
+		match:    MatchMethod | MatchPath | MatchQuery,
+		redactor: DefaultRedactor(),
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	c := &CassetteRequester{
+		path: path,
+		mode: mode,
+		opts: opts,
+	}
+	if mode != CassetteReplay && opts.requester == nil {
+		return nil, errors.New("requester: cassette record and passthrough modes need a requester")
+	}
+	if mode == CassetteReplay {
+		err := c.load()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
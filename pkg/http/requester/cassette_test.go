@@ -0,0 +1,134 @@
+package requester
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+)
+
+func Test_CassetteRequester(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+	}{
+		{
+			name: "json cassette",
+			file: "cassette.json",
+		},
+		{
+			name: "edn cassette",
+			file: "cassette.edn",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			calls := 0
+			base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+				calls++
+				return &http.Response{
+					StatusCode: http.StatusCreated,
+					Header: http.Header{
+						"Content-Type": []string{"application/json"},
+					},
+					Body: io.NopCloser(strings.NewReader(`{"id":1,"token":"abc"}`)),
+				}, nil
+			})
+
+			recorder, err := NewCassetteRequester(path, CassetteRecord,
+				WithCassetteRequester(base),
+				WithCassetteMatch(MatchMethod|MatchPath|MatchQuery|MatchBody),
+			)
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			req, _ := http.NewRequest(
+				http.MethodPost,
+				"http://example.com/users?api_key=secret",
+				bytes.NewReader([]byte(`{"name":"a","password":"b"}`)),
+			)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer token")
+			res, err := recorder.Do(req)
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			body, _ := io.ReadAll(res.Body)
+			assert.Equal(t, `{"id":1,"token":"abc"}`, string(body))
+
+			interactions := recorder.Interactions()
+			if !assert.Len(t, interactions, 1) {
+				return
+			}
+			assert.Equal(t, "http://example.com/users?api_key=%5BREDACTED%5D", interactions[0].Request.URL)
+			assert.Equal(t, "[REDACTED]", interactions[0].Request.Header.Get("Authorization"))
+			assert.Equal(t, `{"name":"a","password":"[REDACTED]"}`, interactions[0].Request.Body)
+			assert.Equal(t, `{"id":1,"token":"[REDACTED]"}`, interactions[0].Response.Body)
+
+			player, err := NewCassetteRequester(path, CassetteReplay,
+				WithCassetteMatch(MatchMethod|MatchPath|MatchQuery|MatchBody),
+			)
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			req, _ = http.NewRequest(
+				http.MethodPost,
+				"http://example.com/users?api_key=other",
+				bytes.NewReader([]byte(`{"name":"a","password":"c"}`)),
+			)
+			req.Header.Set("Content-Type", "application/json")
+			res, err = player.Do(req)
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			assert.Equal(t, http.StatusCreated, res.StatusCode)
+			assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+			body, _ = io.ReadAll(res.Body)
+			assert.Equal(t, `{"id":1,"token":"[REDACTED]"}`, string(body))
+
+			req, _ = http.NewRequest(http.MethodGet, "http://example.com/users", nil)
+			_, err = player.Do(req)
+			assert.ErrorIs(t, err, ErrCassetteInteractionNotFound)
+			assert.Equal(t, 1, calls)
+		})
+	}
+}
+
+func Test_CassetteRequester_BinaryBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	payload := []byte{0xff, 0x00, 0xfe}
+	base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(bytes.NewReader(payload)),
+		}, nil
+	})
+	recorder, err := NewCassetteRequester(path, CassetteRecord, WithCassetteRequester(base))
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/file", nil)
+	_, err = recorder.Do(req)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, base64BodyEncoding, recorder.Interactions()[0].Response.BodyEncoding)
+
+	player, err := NewCassetteRequester(path, CassetteReplay)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	res, err := player.Do(req)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, payload, body)
+}
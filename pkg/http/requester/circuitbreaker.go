@@ -0,0 +1,307 @@
+package requester
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var ErrCircuitOpen = errors.New("requester: circuit open")
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+type circuitBreakerOptions struct {
+	consecutiveFailures int
+	failureRatio        float64
+	minRequests         int
+	window              time.Duration
+	windowBuckets       int
+	cooldown            time.Duration
+	halfOpenRequests    int
+	isFailure           func(res *http.Response, err error) bool
+	now                 func() time.Time
+}
+
+type CircuitBreakerOption func(opts *circuitBreakerOptions)
+
+func WithConsecutiveFailures(failures int) CircuitBreakerOption {
+	return func(opts *circuitBreakerOptions) {
+		opts.consecutiveFailures = failures
+	}
+}
+
+func WithFailureRatio(ratio float64, minRequests int, window time.Duration) CircuitBreakerOption {
+	return func(opts *circuitBreakerOptions) {
+		opts.failureRatio = ratio
+		opts.minRequests = minRequests
+		opts.window = window
+	}
+}
+
+func WithCooldown(cooldown time.Duration) CircuitBreakerOption {
+	return func(opts *circuitBreakerOptions) {
+		opts.cooldown = cooldown
+	}
+}
+
+func WithHalfOpenRequests(requests int) CircuitBreakerOption {
+	return func(opts *circuitBreakerOptions) {
+		opts.halfOpenRequests = requests
+	}
+}
+
+func WithFailureFn(isFailure func(res *http.Response, err error) bool) CircuitBreakerOption {
+	return func(opts *circuitBreakerOptions) {
+		opts.isFailure = isFailure
+	}
+}
+
+func defaultCircuitBreakerOptions() *circuitBreakerOptions {
+	return &circuitBreakerOptions{
+		consecutiveFailures: 5,
+		failureRatio:        0.5,
+		minRequests:         20,
+		window:              time.Minute,
+		windowBuckets:       10,
+		cooldown:            30 * time.Second,
+		halfOpenRequests:    1,
+		isFailure:           defaultIsFailure,
+		now:                 time.Now,
+	}
+}
+
+func defaultIsFailure(res *http.Response, err error) bool {
+	return err != nil || res.StatusCode >= http.StatusInternalServerError
+}
+
+type circuitOutcome int
+
+const (
+	circuitSuccess circuitOutcome = iota
+	circuitFailure
+	// circuitIgnored is a call the caller gave up on, which says nothing of the host.
+	circuitIgnored
+)
+
+// outcome ignores requests whose context was canceled, while deadlines and
+// timeouts with a cause still count as the host being too slow.
+func (c *CircuitBreakerRequester) outcome(req *http.Request, res *http.Response, err error) circuitOutcome {
+	ctx := req.Context()
+	if err != nil && ctx.Err() != nil && errors.Is(context.Cause(ctx), context.Canceled) {
+		return circuitIgnored
+	}
+	if c.opts.isFailure(res, err) {
+		return circuitFailure
+	}
+	return circuitSuccess
+}
+
+type circuitBucket struct {
+	start    time.Time
+	total    int
+	failures int
+}
+
+type circuitBreaker struct {
+	lock             sync.Mutex
+	opts             *circuitBreakerOptions
+	state            CircuitState
+	openedAt         time.Time
+	consecutive      int
+	halfOpenInFlight int
+	halfOpenSuccess  int
+	buckets          []circuitBucket
+}
+
+func newCircuitBreaker(opts *circuitBreakerOptions) *circuitBreaker {
+	return &circuitBreaker{
+		opts:    opts,
+		buckets: make([]circuitBucket, opts.windowBuckets),
+	}
+}
+
+func (b *circuitBreaker) currentState(now time.Time) CircuitState {
+	if b.state == CircuitOpen && now.Sub(b.openedAt) >= b.opts.cooldown {
+		b.state = CircuitHalfOpen
+		b.halfOpenInFlight = 0
+		b.halfOpenSuccess = 0
+	}
+	return b.state
+}
+
+func (b *circuitBreaker) allow() (CircuitState, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	state := b.currentState(b.opts.now())
+	switch state {
+	case CircuitOpen:
+		return state, false
+	case CircuitHalfOpen:
+		if b.halfOpenInFlight >= b.opts.halfOpenRequests {
+			return state, false
+		}
+		b.halfOpenInFlight++
+	}
+	return state, true
+}
+
+func (b *circuitBreaker) bucket(now time.Time) *circuitBucket {
+	size := b.opts.window / time.Duration(len(b.buckets))
+	if size <= 0 {
+		size = time.Nanosecond
+	}
+	start := now.Truncate(size)
+	bucket := &b.buckets[int(start.UnixNano()/int64(size))%len(b.buckets)]
+	if !bucket.start.Equal(start) {
+		*bucket = circuitBucket{start: start}
+	}
+	return bucket
+}
+
+func (b *circuitBreaker) ratio(now time.Time) (total int, failures int) {
+	for _, bucket := range b.buckets {
+		if now.Sub(bucket.start) < b.opts.window {
+			total += bucket.total
+			failures += bucket.failures
+		}
+	}
+	return total, failures
+}
+
+func (b *circuitBreaker) open(now time.Time) {
+	b.state = CircuitOpen
+	b.openedAt = now
+	b.consecutive = 0
+	b.buckets = make([]circuitBucket, len(b.buckets))
+}
+
+func (b *circuitBreaker) record(from CircuitState, outcome circuitOutcome) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := b.opts.now()
+
+	if from == CircuitHalfOpen {
+		if b.state != CircuitHalfOpen {
+			return
+		}
+		b.halfOpenInFlight--
+		switch outcome {
+		case circuitIgnored:
+			return
+		case circuitFailure:
+			b.open(now)
+			return
+		}
+		b.halfOpenSuccess++
+		if b.halfOpenSuccess >= b.opts.halfOpenRequests {
+			b.state = CircuitClosed
+			b.consecutive = 0
+		}
+		return
+	}
+
+	if b.state != CircuitClosed || outcome == circuitIgnored {
+		return
+	}
+
+	bucket := b.bucket(now)
+	bucket.total++
+	if outcome == circuitSuccess {
+		b.consecutive = 0
+		return
+	}
+	bucket.failures++
+	b.consecutive++
+
+	if b.opts.consecutiveFailures > 0 && b.consecutive >= b.opts.consecutiveFailures {
+		b.open(now)
+		return
+	}
+	if b.opts.failureRatio > 0 {
+		total, failures := b.ratio(now)
+		if total >= b.opts.minRequests && float64(failures)/float64(total) >= b.opts.failureRatio {
+			b.open(now)
+		}
+	}
+}
+
+type CircuitBreakerRequester struct {
+	requester Requester
+	opts      *circuitBreakerOptions
+	lock      sync.Mutex
+	breakers  map[string]*circuitBreaker
+}
+
+func (c *CircuitBreakerRequester) breaker(host string) *circuitBreaker {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	b, ok := c.breakers[host]
+	if !ok {
+		b = newCircuitBreaker(c.opts)
+		c.breakers[host] = b
+	}
+	return b
+}
+
+func (c *CircuitBreakerRequester) State(host string) CircuitState {
+	b := c.breaker(host)
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.currentState(c.opts.now())
+}
+
+func (c *CircuitBreakerRequester) Do(req *http.Request) (*http.Response, error) {
+	b := c.breaker(req.URL.Host)
+	state, ok := b.allow()
+	if !ok {
+		return nil, errors.Wrapf(ErrCircuitOpen, "host %s", req.URL.Host)
+	}
+	resp, err := c.requester.Do(req)
+	b.record(state, c.outcome(req, resp, err))
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func NewCircuitBreakerRequester(
+	requester Requester,
+	options ...CircuitBreakerOption,
+) *CircuitBreakerRequester {
+	opts := defaultCircuitBreakerOptions()
+	for _, option := range options {
+		option(opts)
+	}
+	if opts.windowBuckets < 1 {
+		opts.windowBuckets = 1
+	}
+	if opts.halfOpenRequests < 1 {
+		opts.halfOpenRequests = 1
+	}
+	return &CircuitBreakerRequester{
+		requester: requester,
+		opts:      opts,
+		breakers:  map[string]*circuitBreaker{},
+	}
+}
@@ -0,0 +1,118 @@
+package requester
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+type statusRequester struct {
+	status int
+	calls  int
+}
+
+func (r *statusRequester) Do(req *http.Request) (*http.Response, error) {
+	r.calls++
+	return &http.Response{
+		StatusCode: r.status,
+		Body:       http.NoBody,
+	}, nil
+}
+
+func Test_CircuitBreakerRequester(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := &statusRequester{status: 500}
+	c := NewCircuitBreakerRequester(base,
+		WithConsecutiveFailures(2),
+		WithCooldown(time.Minute),
+	)
+	c.opts.now = func() time.Time { return now }
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/a", nil)
+	other, _ := http.NewRequest(http.MethodGet, "http://other.com/a", nil)
+
+	for i := 0; i < 2; i++ {
+		_, err := c.Do(req)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, CircuitOpen, c.State("example.com"))
+
+	_, err := c.Do(req)
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Equal(t, 2, base.calls)
+
+	_, err = c.Do(other)
+	assert.Nil(t, err)
+	assert.Equal(t, CircuitClosed, c.State("other.com"))
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, c.State("example.com"))
+
+	_, err = c.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, CircuitOpen, c.State("example.com"))
+
+	now = now.Add(time.Minute)
+	base.status = 200
+	_, err = c.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, CircuitClosed, c.State("example.com"))
+}
+
+func Test_CircuitBreakerRequester_FailureRatio(t *testing.T) {
+	base := &statusRequester{status: 200}
+	c := NewCircuitBreakerRequester(base,
+		WithConsecutiveFailures(0),
+		WithFailureRatio(0.5, 4, time.Minute),
+	)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/a", nil)
+
+	statuses := []int{200, 500, 200, 500}
+	for _, status := range statuses {
+		base.status = status
+		_, err := c.Do(req)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, CircuitOpen, c.State("example.com"))
+}
+
+func Test_CircuitBreakerRequester_CallerGaveUp(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewCircuitBreakerRequester(
+		RequesterFunc(func(req *http.Request) (*http.Response, error) {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}),
+		WithConsecutiveFailures(2),
+		WithCooldown(time.Minute),
+	)
+	c.opts.now = func() time.Time { return now }
+	do := func(cancelCall bool) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		if cancelCall {
+			cancel()
+		}
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/a", nil)
+		_, _ = c.Do(req)
+	}
+
+	// canceled calls neither fail nor reset the consecutive failures
+	do(false)
+	do(true)
+	assert.Equal(t, CircuitClosed, c.State("example.com"))
+	do(false)
+	assert.Equal(t, CircuitOpen, c.State("example.com"))
+
+	// a canceled probe only releases the half open slot
+	now = now.Add(time.Minute)
+	do(true)
+	assert.Equal(t, CircuitHalfOpen, c.State("example.com"))
+	do(false)
+	assert.Equal(t, CircuitOpen, c.State("example.com"))
+}
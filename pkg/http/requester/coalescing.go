@@ -0,0 +1,173 @@
+package requester
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+type coalescingOptions struct {
+	headers []string
+	methods []string
+}
+
+type CoalescingOption func(opts *coalescingOptions)
+
+// credentialHeaders are always part of the key, so a response fetched with
+// one caller credentials is never handed to another caller.
+var credentialHeaders = []string{"Authorization", "Cookie"}
+
+// WithCoalescingHeaders makes the given headers part of the key, so requests
+// with different values for them are never shared.
+func WithCoalescingHeaders(headers ...string) CoalescingOption {
+	return func(opts *coalescingOptions) {
+		opts.headers = headers
+	}
+}
+
+func WithCoalescingMethods(methods ...string) CoalescingOption {
+	return func(opts *coalescingOptions) {
+		opts.methods = methods
+	}
+}
+
+type coalescingCall struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+	res     *http.Response
+	body    []byte
+	err     error
+}
+
+type coalescingRequester struct {
+	requester Requester
+	opts      *coalescingOptions
+	lock      sync.Mutex
+	calls     map[string]*coalescingCall
+}
+
+func (c *coalescingRequester) key(req *http.Request) string {
+	builder := strings.Builder{}
+	builder.WriteString(req.Method)
+	builder.WriteString(" ")
+	builder.WriteString(req.URL.String())
+	for _, header := range append(credentialHeaders, c.opts.headers...) {
+		builder.WriteString("\n")
+		builder.WriteString(http.CanonicalHeaderKey(header))
+		builder.WriteString(": ")
+		builder.WriteString(strings.Join(req.Header.Values(header), ", "))
+	}
+	return builder.String()
+}
+
+func (c *coalescingRequester) coalesces(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody {
+		return false
+	}
+	for _, method := range c.opts.methods {
+		if method == req.Method {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *coalescingRequester) run(key string, call *coalescingCall, req *http.Request) {
+	res, err := c.requester.Do(req)
+	if err == nil {
+		call.body, err = io.ReadAll(res.Body)
+		_ = res.Body.Close()
+	}
+	call.res = res
+	call.err = err
+
+	c.lock.Lock()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	c.lock.Unlock()
+	close(call.done)
+}
+
+// leave cancels the upstream request once every waiter has given up on it.
+func (c *coalescingRequester) leave(key string, call *coalescingCall) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	call.cancel()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+}
+
+func (c *coalescingRequester) Do(req *http.Request) (*http.Response, error) {
+	if !c.coalesces(req) {
+		return c.requester.Do(req)
+	}
+
+	key := c.key(req)
+	c.lock.Lock()
+	call, ok := c.calls[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(context.WithoutCancel(req.Context()))
+		call = &coalescingCall{
+			done:   make(chan struct{}),
+			cancel: cancel,
+		}
+		c.calls[key] = call
+		go c.run(key, call, req.Clone(ctx))
+	}
+	call.waiters++
+	c.lock.Unlock()
+
+	select {
+	case <-call.done:
+	case <-req.Context().Done():
+		c.leave(key, call)
+		return nil, req.Context().Err()
+	}
+	c.leave(key, call)
+
+	if call.err != nil {
+		return nil, call.err
+	}
+	res := *call.res
+	res.Header = call.res.Header.Clone()
+	res.Trailer = call.res.Trailer.Clone()
+	res.Body = io.NopCloser(bytes.NewReader(call.body))
+	res.ContentLength = int64(len(call.body))
+	res.Request = req
+	return &res, nil
+}
+
+// NewCoalescingRequester shares a single upstream call between concurrent
+// identical requests, by default only GET and HEAD without body are shared.
+func NewCoalescingRequester(
+	requester Requester,
+	options ...CoalescingOption,
+) Requester {
+	opts := &coalescingOptions{
+		methods: []string{http.MethodGet, http.MethodHead},
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	return &coalescingRequester{
+		requester: requester,
+		opts:      opts,
+		calls:     map[string]*coalescingCall{},
+	}
+}
+
+func CoalescingMiddleware(options ...CoalescingOption) Middleware {
+	return func(requester Requester) Requester {
+		return NewCoalescingRequester(requester, options...)
+	}
+}
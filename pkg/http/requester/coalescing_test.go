@@ -0,0 +1,133 @@
+package requester
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CoalescingRequester(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		<-release
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("shared")),
+		}, nil
+	})
+	requester := NewCoalescingRequester(base, WithCoalescingHeaders("Accept"))
+
+	bodies := make([]string, 5)
+	wg := sync.WaitGroup{}
+	for idx := range bodies {
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com/resource", nil)
+			req.Header.Set("Accept", "application/json")
+			res, err := requester.Do(req)
+			if !assert.Nil(t, err) {
+				return
+			}
+			body, _ := io.ReadAll(res.Body)
+			bodies[idx] = string(body)
+		}(idx)
+	}
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, []string{"shared", "shared", "shared", "shared", "shared"}, bodies)
+}
+
+func Test_CoalescingRequester_Cancel(t *testing.T) {
+	upstreamCanceled := make(chan struct{})
+	base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		close(upstreamCanceled)
+		return nil, req.Context().Err()
+	})
+	requester := NewCoalescingRequester(base)
+
+	ctxA, cancelA := context.WithCancel(context.Background())
+	ctxB, cancelB := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	for _, ctx := range []context.Context{ctxA, ctxB} {
+		go func(ctx context.Context) {
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com/resource", nil)
+			_, err := requester.Do(req)
+			errs <- err
+		}(ctx)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	cancelA()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+	select {
+	case <-upstreamCanceled:
+		t.Fatal("upstream canceled while a waiter remains")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	cancelB()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+	select {
+	case <-upstreamCanceled:
+	case <-time.After(time.Second):
+		t.Fatal("upstream not canceled")
+	}
+}
+
+func Test_CoalescingRequester_Credentials(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		<-release
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(req.Header.Get("Authorization"))),
+		}, nil
+	})
+	requester := NewCoalescingRequester(base)
+
+	tokens := []string{"Bearer a", "Bearer b"}
+	bodies := make([]string, len(tokens))
+	wg := sync.WaitGroup{}
+	for idx, token := range tokens {
+		wg.Add(1)
+		go func(idx int, token string) {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, "http://example.com/resource", nil)
+			req.Header.Set("Authorization", token)
+			res, err := requester.Do(req)
+			if !assert.Nil(t, err) {
+				return
+			}
+			body, _ := io.ReadAll(res.Body)
+			bodies[idx] = string(body)
+		}(idx, token)
+	}
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 2
+	}, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, tokens, bodies)
+}
@@ -0,0 +1,58 @@
+package requester
+
+import (
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// CurlCommand renders req as a curl command line. Headers, query params and
+// body fields known by redactor are masked, a nil redactor keeps everything.
+func CurlCommand(req *http.Request, redactor *Redactor) (string, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{"curl"}
+	if req.Method != http.MethodGet || len(body) > 0 {
+		parts = append(parts, "-X", req.Method)
+	}
+	parts = append(parts, shellQuote(redactor.URL(req.URL)))
+
+	header := redactor.Header(req.Header)
+	if req.Host != "" && req.Host != req.URL.Host {
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Set("Host", req.Host)
+	}
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			parts = append(parts, "-H", shellQuote(key+": "+value))
+		}
+	}
+
+	if len(body) > 0 {
+		body = redactor.Body(req.Header.Get("Content-Type"), body)
+		if utf8.Valid(body) {
+			parts = append(parts, "--data-raw", shellQuote(string(body)))
+		} else {
+			parts = append(parts,
+				"--data-binary", "@<(echo "+base64.StdEncoding.EncodeToString(body)+" | base64 -d)",
+			)
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
@@ -0,0 +1,70 @@
+package requester
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+)
+
+func Test_CurlCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		url      string
+		body     string
+		headers  http.Header
+		redactor *Redactor
+		want     string
+	}{
+		{
+			name:   "simple get",
+			method: http.MethodGet,
+			url:    "http://example.com/users?page=1",
+			want:   `curl 'http://example.com/users?page=1'`,
+		},
+		{
+			name:   "post with quoted body",
+			method: http.MethodPost,
+			url:    "http://example.com/users",
+			body:   `{"name":"o'neil"}`,
+			headers: http.Header{
+				"Content-Type":  []string{"application/json"},
+				"Authorization": []string{"Bearer token"},
+			},
+			want: `curl -X POST 'http://example.com/users' -H 'Authorization: Bearer token' -H 'Content-Type: application/json' --data-raw '{"name":"o'\''neil"}'`,
+		},
+		{
+			name:   "redacted",
+			method: http.MethodPost,
+			url:    "http://example.com/login?api_key=abc",
+			body:   `{"password":"secret"}`,
+			headers: http.Header{
+				"Content-Type":  []string{"application/json"},
+				"Authorization": []string{"Bearer token"},
+			},
+			redactor: DefaultRedactor(),
+			want:     `curl -X POST 'http://example.com/login?api_key=%5BREDACTED%5D' -H 'Authorization: [REDACTED]' -H 'Content-Type: application/json' --data-raw '{"password":"[REDACTED]"}'`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req *http.Request
+			if tt.body != "" {
+				req, _ = http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			} else {
+				req, _ = http.NewRequest(tt.method, tt.url, nil)
+			}
+			for key, values := range tt.headers {
+				req.Header[key] = values
+			}
+			got, err := CurlCommand(req, tt.redactor)
+			if !assertutil.Error(t, nil, err) {
+				return
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
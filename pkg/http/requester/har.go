@@ -0,0 +1,283 @@
+package requester
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/vitorsss/go-helpers/pkg/files"
+)
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+type HAREntry struct {
+	StartedDateTime time.Time      `json:"startedDateTime"`
+	Time            float64        `json:"time"`
+	Request         HARRequest     `json:"request"`
+	Response        HARResponse    `json:"response"`
+	Cache           map[string]any `json:"cache"`
+	Timings         HARTimings     `json:"timings"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+func harHeaders(header http.Header) []HARNameValue {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := []HARNameValue{}
+	for _, key := range keys {
+		for _, value := range header[key] {
+			result = append(result, HARNameValue{Name: key, Value: value})
+		}
+	}
+	return result
+}
+
+func harProto(proto string) string {
+	if proto == "" {
+		return "HTTP/1.1"
+	}
+	return proto
+}
+
+type harOptions struct {
+	redactor *Redactor
+	creator  HARCreator
+}
+
+type HAROption func(opts *harOptions)
+
+func WithHARRedactor(redactor *Redactor) HAROption {
+	return func(opts *harOptions) {
+		opts.redactor = redactor
+	}
+}
+
+func WithHARCreator(name string, version string) HAROption {
+	return func(opts *harOptions) {
+		opts.creator = HARCreator{
+			Name:    name,
+			Version: version,
+		}
+	}
+}
+
+type HARRequester struct {
+	requester Requester
+	filePath  string
+	opts      *harOptions
+	lock      sync.Mutex
+	har       HAR
+	flushLock sync.Mutex
+}
+
+func (c *HARRequester) Entries() []HAREntry {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]HAREntry{}, c.har.Log.Entries...)
+}
+
+func (c *HARRequester) entry(
+	req *http.Request,
+	requestBody []byte,
+	res *http.Response,
+	responseBody []byte,
+	started time.Time,
+	duration time.Duration,
+) HAREntry {
+	redactor := c.opts.redactor
+	values := req.URL.Query()
+	if redactor != nil {
+		values = redactor.values(values)
+	}
+	query := []HARNameValue{}
+	for key, items := range values {
+		for _, value := range items {
+			query = append(query, HARNameValue{Name: key, Value: value})
+		}
+	}
+	sort.SliceStable(query, func(i, j int) bool {
+		return query[i].Name < query[j].Name
+	})
+
+	request := HARRequest{
+		Method:      req.Method,
+		URL:         redactor.URL(req.URL),
+		HTTPVersion: harProto(req.Proto),
+		Cookies:     []HARNameValue{},
+		Headers:     harHeaders(redactor.Header(req.Header)),
+		QueryString: query,
+		HeadersSize: -1,
+		BodySize:    int64(len(requestBody)),
+	}
+	if len(requestBody) > 0 {
+		contentType := req.Header.Get("Content-Type")
+		request.PostData = &HARPostData{
+			MimeType: contentType,
+			Text:     string(redactor.Body(contentType, requestBody)),
+		}
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	content := HARContent{
+		Size:     int64(len(responseBody)),
+		MimeType: contentType,
+	}
+	if utf8.Valid(responseBody) {
+		content.Text = string(redactor.Body(contentType, responseBody))
+	} else {
+		content.Text, content.Encoding = encodeBody(responseBody)
+	}
+
+	milliseconds := float64(duration) / float64(time.Millisecond)
+	return HAREntry{
+		StartedDateTime: started,
+		Time:            milliseconds,
+		Request:         request,
+		Response: HARResponse{
+			Status:      res.StatusCode,
+			StatusText:  http.StatusText(res.StatusCode),
+			HTTPVersion: harProto(res.Proto),
+			Cookies:     []HARNameValue{},
+			Headers:     harHeaders(redactor.Header(res.Header)),
+			Content:     content,
+			RedirectURL: res.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    int64(len(responseBody)),
+		},
+		Cache: map[string]any{},
+		Timings: HARTimings{
+			Wait: milliseconds,
+		},
+	}
+}
+
+func (c *HARRequester) Do(req *http.Request) (*http.Response, error) {
+	requestBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	started := time.Now()
+	res, err := c.requester.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := readResponseBody(res)
+	if err != nil {
+		return nil, err
+	}
+	entry := c.entry(req, requestBody, res, responseBody, started, time.Since(started))
+
+	c.lock.Lock()
+	c.har.Log.Entries = append(c.har.Log.Entries, entry)
+	c.lock.Unlock()
+	return res, nil
+}
+
+// Flush writes every entry recorded so far to the HAR file.
+func (c *HARRequester) Flush() error {
+	c.flushLock.Lock()
+	defer c.flushLock.Unlock()
+
+	c.lock.Lock()
+	har := c.har
+	har.Log.Entries = append([]HAREntry{}, c.har.Log.Entries...)
+	c.lock.Unlock()
+
+	return errors.Wrap(files.WriteJSONFile(c.filePath, har), "failed to save har file")
+}
+
+// NewHARRequester records every exchange in memory, Flush must be called to
+// write them to filePath.
+func NewHARRequester(
+	requester Requester,
+	filePath string,
+	options ...HAROption,
+) *HARRequester {
+	opts := &harOptions{
+		redactor: DefaultRedactor(),
+		creator: HARCreator{
+			Name:    "go-helpers",
+			Version: "1.0",
+		},
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	return &HARRequester{
+		requester: requester,
+		filePath:  filePath,
+		opts:      opts,
+		har: HAR{
+			Log: HARLog{
+				Version: "1.2",
+				Creator: opts.creator,
+				Entries: []HAREntry{},
+			},
+		},
+	}
+}
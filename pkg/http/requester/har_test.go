@@ -0,0 +1,86 @@
+package requester
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"github.com/vitorsss/go-helpers/pkg/files"
+)
+
+func Test_HARRequester(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.har")
+	base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				"Content-Type": []string{"application/json"},
+				"Set-Cookie":   []string{"session=abc"},
+			},
+			Body: io.NopCloser(strings.NewReader(`{"access_token":"abc","expires_in":60}`)),
+		}, nil
+	})
+	requester := NewHARRequester(base, path)
+
+	req, _ := http.NewRequest(
+		http.MethodPost,
+		"http://example.com/token?client_secret=abc&scope=read",
+		strings.NewReader(`grant_type=password&password=secret`),
+	)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := requester.Do(req)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	body, _ := io.ReadAll(res.Body)
+	assert.Equal(t, `{"access_token":"abc","expires_in":60}`, string(body))
+	err = requester.Flush()
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+
+	content, err := files.ReadJSONFile[HAR](path)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	har := content.Content
+	assert.Equal(t, "1.2", har.Log.Version)
+	if !assert.Len(t, har.Log.Entries, 1) {
+		return
+	}
+	entry := har.Log.Entries[0]
+	assert.Equal(t, http.MethodPost, entry.Request.Method)
+	assert.Equal(t, "http://example.com/token?client_secret=%5BREDACTED%5D&scope=read", entry.Request.URL)
+	assert.Equal(t, []HARNameValue{
+		{Name: "client_secret", Value: "[REDACTED]"},
+		{Name: "scope", Value: "read"},
+	}, entry.Request.QueryString)
+	assert.Equal(t, "grant_type=password&password=%5BREDACTED%5D", entry.Request.PostData.Text)
+	assert.Equal(t, 200, entry.Response.Status)
+	assert.Equal(t, `{"access_token":"[REDACTED]","expires_in":60}`, entry.Response.Content.Text)
+	assert.Contains(t, entry.Response.Headers, HARNameValue{Name: "Set-Cookie", Value: "[REDACTED]"})
+}
+
+func Test_HARRequester_FlushError(t *testing.T) {
+	blocker := filepath.Join(t.TempDir(), "blocker")
+	if !assertutil.Error(t, nil, os.WriteFile(blocker, nil, 0o600)) {
+		return
+	}
+	// the parent of the har file is a regular file, so writing it fails
+	path := filepath.Join(blocker, "capture.har")
+	requester := NewHARRequester(okRequester(), path)
+
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com/orders", nil)
+	res, err := requester.Do(req)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Len(t, requester.Entries(), 1)
+	assert.Error(t, requester.Flush())
+}
@@ -0,0 +1,275 @@
+package requester
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type hedgingOptions struct {
+	delay      time.Duration
+	percentile float64
+	window     int
+	maxHedges  int
+	limiter    *rate.Limiter
+	methods    []string
+}
+
+type HedgingOption func(opts *hedgingOptions)
+
+func WithHedgeDelay(delay time.Duration) HedgingOption {
+	return func(opts *hedgingOptions) {
+		opts.delay = delay
+	}
+}
+
+// WithHedgePercentile hedges once the first attempt is slower than the given
+// percentile (0 to 1) of the last window latencies, the fixed delay is used
+// until enough latencies are known.
+func WithHedgePercentile(percentile float64, window int) HedgingOption {
+	return func(opts *hedgingOptions) {
+		opts.percentile = percentile
+		opts.window = window
+	}
+}
+
+func WithMaxHedges(maxHedges int) HedgingOption {
+	return func(opts *hedgingOptions) {
+		opts.maxHedges = maxHedges
+	}
+}
+
+// WithHedgeRate caps the hedged requests sent per second across all calls.
+func WithHedgeRate(perSecond rate.Limit, burst int) HedgingOption {
+	return func(opts *hedgingOptions) {
+		opts.limiter = rate.NewLimiter(perSecond, burst)
+	}
+}
+
+func WithHedgeMethods(methods ...string) HedgingOption {
+	return func(opts *hedgingOptions) {
+		opts.methods = methods
+	}
+}
+
+type latencyWindow struct {
+	lock      sync.Mutex
+	latencies []time.Duration
+	next      int
+	size      int
+}
+
+func (w *latencyWindow) add(latency time.Duration) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if len(w.latencies) < w.size {
+		w.latencies = append(w.latencies, latency)
+		return
+	}
+	w.latencies[w.next] = latency
+	w.next = (w.next + 1) % w.size
+}
+
+func (w *latencyWindow) percentile(percentile float64) (time.Duration, bool) {
+	w.lock.Lock()
+	sorted := append([]time.Duration{}, w.latencies...)
+	w.lock.Unlock()
+	if len(sorted) == 0 || len(sorted) < w.size/2 {
+		return 0, false
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	idx := int(math.Ceil(percentile*float64(len(sorted)))) - 1
+	return sorted[max(min(idx, len(sorted)-1), 0)], true
+}
+
+type hedgeResult struct {
+	idx      int
+	res      *http.Response
+	err      error
+	duration time.Duration
+}
+
+func (r hedgeResult) succeeded() bool {
+	return r.err == nil && r.res.StatusCode < http.StatusInternalServerError
+}
+
+func (r hedgeResult) discard() {
+	if r.res != nil && r.res.Body != nil {
+		_, _ = io.Copy(io.Discard, r.res.Body)
+		_ = r.res.Body.Close()
+	}
+}
+
+// hedgeBody cancels the winning attempt context once its body is closed.
+type hedgeBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *hedgeBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+type hedgingRequester struct {
+	requester Requester
+	opts      *hedgingOptions
+	latencies *latencyWindow
+}
+
+func (c *hedgingRequester) hedges(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	for _, method := range c.opts.methods {
+		if method == req.Method {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *hedgingRequester) delay() time.Duration {
+	if c.opts.percentile > 0 {
+		if delay, ok := c.latencies.percentile(c.opts.percentile); ok {
+			return delay
+		}
+	}
+	return c.opts.delay
+}
+
+func (c *hedgingRequester) Do(req *http.Request) (*http.Response, error) {
+	if !c.hedges(req) {
+		return c.requester.Do(req)
+	}
+
+	ctx, cancel := context.WithCancel(req.Context())
+	results := make(chan hedgeResult, c.opts.maxHedges+1)
+	cancels := []context.CancelFunc{}
+	launch := func() {
+		attemptCtx, attemptCancel := context.WithCancel(ctx)
+		cancels = append(cancels, attemptCancel)
+		attemptReq := req.Clone(attemptCtx)
+		idx := len(cancels) - 1
+		if idx > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				results <- hedgeResult{idx: idx, err: err}
+				return
+			}
+			attemptReq.Body = body
+		}
+		go func() {
+			start := time.Now()
+			res, err := c.requester.Do(attemptReq)
+			results <- hedgeResult{
+				idx:      idx,
+				res:      res,
+				err:      err,
+				duration: time.Since(start),
+			}
+		}()
+	}
+
+	started := time.Now()
+	launch()
+	inFlight := 1
+	delay := c.delay()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	var last hedgeResult
+	for {
+		select {
+		case <-timer.C:
+			if len(cancels) <= c.opts.maxHedges && c.opts.limiter.Allow() {
+				launch()
+				inFlight++
+				timer.Reset(delay)
+			}
+		case result := <-results:
+			inFlight--
+			if !result.succeeded() && inFlight > 0 {
+				last.discard()
+				last = result
+				continue
+			}
+			if result.succeeded() {
+				latency := result.duration
+				if result.idx > 0 {
+					// the first attempt is still running, so its elapsed time is
+					// recorded as a lower bound instead of the hedge latency
+					latency = time.Since(started)
+				}
+				c.latencies.add(latency)
+			}
+			last.discard()
+			for idx, attemptCancel := range cancels {
+				if idx != result.idx {
+					attemptCancel()
+				}
+			}
+			go func(pending int) {
+				for ; pending > 0; pending-- {
+					(<-results).discard()
+				}
+			}(inFlight)
+
+			if result.err != nil {
+				cancel()
+				return nil, result.err
+			}
+			if result.res.Body == nil {
+				cancel()
+				return result.res, nil
+			}
+			result.res.Body = &hedgeBody{
+				ReadCloser: result.res.Body,
+				cancel:     cancel,
+			}
+			return result.res, nil
+		}
+	}
+}
+
+// NewHedgingRequester sends another identical request when the previous ones
+// did not answer within the hedge delay, the first successful response wins
+// and the other attempts are canceled. Responses with 5xx status count as
+// failures while other attempts are still running.
+func NewHedgingRequester(
+	requester Requester,
+	options ...HedgingOption,
+) Requester {
+	opts := &hedgingOptions{
+		delay:     100 * time.Millisecond,
+		window:    100,
+		maxHedges: 1,
+		limiter:   rate.NewLimiter(10, 10),
+		methods:   []string{http.MethodGet, http.MethodHead},
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	return &hedgingRequester{
+		requester: requester,
+		opts:      opts,
+		latencies: &latencyWindow{
+			size: max(opts.window, 1),
+		},
+	}
+}
+
+func HedgingMiddleware(options ...HedgingOption) Middleware {
+	return func(requester Requester) Requester {
+		return NewHedgingRequester(requester, options...)
+	}
+}
@@ -0,0 +1,122 @@
+package requester
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+)
+
+func Test_HedgingRequester(t *testing.T) {
+	var calls atomic.Int32
+	firstCanceled := make(chan struct{})
+	base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			<-req.Context().Done()
+			close(firstCanceled)
+			return nil, req.Context().Err()
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("hedged")),
+		}, nil
+	})
+	requester := NewHedgingRequester(base, WithHedgeDelay(10*time.Millisecond))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	res, err := requester.Do(req)
+	if !assertutil.Error(t, nil, err) {
+		return
+	}
+	body, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	assert.Equal(t, "hedged", string(body))
+	assert.Equal(t, int32(2), calls.Load())
+
+	select {
+	case <-firstCanceled:
+	case <-time.After(time.Second):
+		t.Fatal("losing attempt not canceled")
+	}
+}
+
+func Test_HedgingRequester_RateCap(t *testing.T) {
+	var calls atomic.Int32
+	base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		time.Sleep(30 * time.Millisecond)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+	requester := NewHedgingRequester(base,
+		WithHedgeDelay(5*time.Millisecond),
+		WithHedgeRate(0, 1),
+	)
+
+	for idx := 0; idx < 3; idx++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		res, err := requester.Do(req)
+		if !assertutil.Error(t, nil, err) {
+			return
+		}
+		_ = res.Body.Close()
+	}
+	assert.Eventually(t, func() bool {
+		return calls.Load() == 4
+	}, time.Second, time.Millisecond)
+}
+
+func Test_HedgingRequester_PercentileSlowPrimaries(t *testing.T) {
+	var calls atomic.Int32
+	base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		if calls.Add(1)%2 == 1 {
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(time.Second):
+			}
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+	requester := NewHedgingRequester(base,
+		WithHedgeDelay(20*time.Millisecond),
+		WithHedgePercentile(0.5, 4),
+		WithHedgeRate(1000, 1000),
+	)
+
+	for idx := 0; idx < 6; idx++ {
+		req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+		res, err := requester.Do(req)
+		if !assertutil.Error(t, nil, err) {
+			return
+		}
+		_ = res.Body.Close()
+	}
+	assert.Equal(t, int32(12), calls.Load())
+	assert.GreaterOrEqual(t, requester.(*hedgingRequester).delay(), 20*time.Millisecond)
+}
+
+func Test_latencyWindow_percentile(t *testing.T) {
+	window := &latencyWindow{size: 4}
+	_, ok := window.percentile(0.5)
+	assert.False(t, ok)
+
+	for _, latency := range []int{50, 10, 40, 20, 30} {
+		window.add(time.Duration(latency) * time.Millisecond)
+	}
+	p50, ok := window.percentile(0.5)
+	assert.True(t, ok)
+	assert.Equal(t, 20*time.Millisecond, p50)
+	p99, _ := window.percentile(0.99)
+	assert.Equal(t, 40*time.Millisecond, p99)
+}
@@ -0,0 +1,130 @@
+package requester
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type KeyFn func(req *http.Request) string
+
+func HostKey(req *http.Request) string {
+	return req.URL.Host
+}
+
+func RouteKey(req *http.Request) string {
+	return req.Method + " " + req.URL.Host + req.URL.Path
+}
+
+// ContextKey reads the bucket from a context value, such as a tenant id.
+func ContextKey(key any) KeyFn {
+	return func(req *http.Request) string {
+		value := req.Context().Value(key)
+		if value == nil {
+			return ""
+		}
+		return fmt.Sprint(value)
+	}
+}
+
+type keyedRateLimitOptions struct {
+	idleTimeout time.Duration
+	now         func() time.Time
+}
+
+type KeyedRateLimitOption func(opts *keyedRateLimitOptions)
+
+// WithKeyedIdleTimeout evicts the limiters of keys not used for the duration.
+func WithKeyedIdleTimeout(idleTimeout time.Duration) KeyedRateLimitOption {
+	return func(opts *keyedRateLimitOptions) {
+		opts.idleTimeout = idleTimeout
+	}
+}
+
+func WithKeyedClock(now func() time.Time) KeyedRateLimitOption {
+	return func(opts *keyedRateLimitOptions) {
+		opts.now = now
+	}
+}
+
+type keyedBucket struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+type keyedRateLimitedRequester struct {
+	requester  Requester
+	keyFn      KeyFn
+	newLimiter func(key string) *rate.Limiter
+	opts       *keyedRateLimitOptions
+	lock       sync.Mutex
+	buckets    map[string]*keyedBucket
+	lastSweep  time.Time
+}
+
+func (c *keyedRateLimitedRequester) limiter(key string) *rate.Limiter {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := c.opts.now()
+	if c.opts.idleTimeout > 0 && now.Sub(c.lastSweep) >= c.opts.idleTimeout {
+		c.lastSweep = now
+		for bucketKey, bucket := range c.buckets {
+			if now.Sub(bucket.lastUsed) >= c.opts.idleTimeout {
+				delete(c.buckets, bucketKey)
+			}
+		}
+	}
+	bucket, ok := c.buckets[key]
+	if !ok {
+		bucket = &keyedBucket{
+			limiter: c.newLimiter(key),
+		}
+		c.buckets[key] = bucket
+	}
+	bucket.lastUsed = now
+	return bucket.limiter
+}
+
+func (c *keyedRateLimitedRequester) Do(req *http.Request) (*http.Response, error) {
+	err := c.limiter(c.keyFn(req)).Wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	return c.requester.Do(req)
+}
+
+func NewKeyedRateLimitedRequester(
+	requester Requester,
+	keyFn KeyFn,
+	newLimiter func(key string) *rate.Limiter,
+	options ...KeyedRateLimitOption,
+) Requester {
+	opts := &keyedRateLimitOptions{
+		idleTimeout: 10 * time.Minute,
+		now:         time.Now,
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	return &keyedRateLimitedRequester{
+		requester:  requester,
+		keyFn:      keyFn,
+		newLimiter: newLimiter,
+		opts:       opts,
+		buckets:    map[string]*keyedBucket{},
+		lastSweep:  opts.now(),
+	}
+}
+
+func KeyedRateLimitMiddleware(
+	keyFn KeyFn,
+	newLimiter func(key string) *rate.Limiter,
+	options ...KeyedRateLimitOption,
+) Middleware {
+	return func(requester Requester) Requester {
+		return NewKeyedRateLimitedRequester(requester, keyFn, newLimiter, options...)
+	}
+}
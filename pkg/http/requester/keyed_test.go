@@ -0,0 +1,69 @@
+package requester
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"golang.org/x/time/rate"
+)
+
+type tenantKey struct{}
+
+func okRequester() Requester {
+	return RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+}
+
+func Test_KeyedRateLimitedRequester(t *testing.T) {
+	now := time.Now()
+	requester := NewKeyedRateLimitedRequester(
+		okRequester(),
+		ContextKey(tenantKey{}),
+		func(key string) *rate.Limiter {
+			return rate.NewLimiter(rate.Every(time.Hour), 1)
+		},
+		WithKeyedIdleTimeout(time.Minute),
+		WithKeyedClock(func() time.Time {
+			return now
+		}),
+	)
+
+	do := func(tenant string) error {
+		ctx, cancel := context.WithTimeout(
+			context.WithValue(context.Background(), tenantKey{}, tenant),
+			10*time.Millisecond,
+		)
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.com", nil)
+		_, err := requester.Do(req)
+		return err
+	}
+
+	assertutil.Error(t, nil, do("a"))
+	assertutil.Error(t, nil, do("b"))
+	assert.Error(t, do("a"))
+
+	keyed := requester.(*keyedRateLimitedRequester)
+	assert.Len(t, keyed.buckets, 2)
+
+	now = now.Add(2 * time.Minute)
+	assertutil.Error(t, nil, do("a"))
+	assert.Len(t, keyed.buckets, 1)
+}
+
+func Test_Keys(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "http://example.com/items?page=1", nil)
+	assert.Equal(t, "example.com", HostKey(req))
+	assert.Equal(t, "POST example.com/items", RouteKey(req))
+	assert.Equal(t, "", ContextKey(tenantKey{})(req))
+}
@@ -0,0 +1,204 @@
+package requester
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/vitorsss/go-helpers/pkg/logs"
+)
+
+type loggingOptions struct {
+	logger      zerolog.Logger
+	level       zerolog.Level
+	errorLevel  zerolog.Level
+	maxBodySize int
+	redactor    *Redactor
+}
+
+type LoggingOption func(opts *loggingOptions)
+
+func WithLogger(logger zerolog.Logger) LoggingOption {
+	return func(opts *loggingOptions) {
+		opts.logger = logger
+	}
+}
+
+func WithLogLevels(level zerolog.Level, errorLevel zerolog.Level) LoggingOption {
+	return func(opts *loggingOptions) {
+		opts.level = level
+		opts.errorLevel = errorLevel
+	}
+}
+
+func WithLogBodies(maxBodySize int) LoggingOption {
+	return func(opts *loggingOptions) {
+		opts.maxBodySize = maxBodySize
+	}
+}
+
+func WithLogRedactor(redactor *Redactor) LoggingOption {
+	return func(opts *loggingOptions) {
+		opts.redactor = redactor
+	}
+}
+
+// streamingMediaTypes are not peeked, their content arrives over time and
+// waiting for it would stall the caller.
+var streamingMediaTypes = []string{
+	"text/event-stream",
+	"application/x-ndjson",
+	"application/ndjson",
+	"application/x-ndedn",
+}
+
+func isStreaming(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return slices.Contains(streamingMediaTypes, mediaType)
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// peekBody reads up to maxSize bytes and puts them back in front of the
+// remaining content, so the body can still be consumed afterwards.
+func peekBody(body io.ReadCloser, maxSize int) ([]byte, bool, io.ReadCloser, error) {
+	if body == nil || body == http.NoBody {
+		return nil, false, body, nil
+	}
+	peeked, err := io.ReadAll(io.LimitReader(body, int64(maxSize)+1))
+	restored := &readCloser{
+		Reader: io.MultiReader(bytes.NewReader(peeked), body),
+		Closer: body,
+	}
+	if err != nil {
+		return nil, false, restored, err
+	}
+	if len(peeked) > maxSize {
+		return peeked[:maxSize], true, restored, nil
+	}
+	return peeked, false, restored, nil
+}
+
+func peekRequestBody(req *http.Request, maxSize int) ([]byte, bool, error) {
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, false, err
+		}
+		defer body.Close()
+		peeked, truncated, _, err := peekBody(body, maxSize)
+		return peeked, truncated, err
+	}
+	peeked, truncated, body, err := peekBody(req.Body, maxSize)
+	req.Body = body
+	return peeked, truncated, err
+}
+
+type loggingRequester struct {
+	requester Requester
+	opts      *loggingOptions
+}
+
+func (c *loggingRequester) logBody(
+	event *zerolog.Event,
+	key string,
+	contentType string,
+	body []byte,
+	truncated bool,
+) {
+	if body == nil {
+		return
+	}
+	event.Bytes(key, c.opts.redactor.Body(contentType, body))
+	if truncated {
+		event.Bool(key+"_truncated", true)
+	}
+}
+
+func (c *loggingRequester) Do(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	var requestTruncated bool
+	var peekErr error
+	if c.opts.maxBodySize > 0 {
+		requestBody, requestTruncated, peekErr = peekRequestBody(req, c.opts.maxBodySize)
+	}
+
+	start := time.Now()
+	resp, err := c.requester.Do(req)
+	duration := time.Since(start)
+
+	level := c.opts.level
+	if err != nil || resp.StatusCode >= http.StatusBadRequest {
+		level = c.opts.errorLevel
+	}
+	event := c.opts.logger.WithLevel(level)
+	if event == nil {
+		return resp, err
+	}
+
+	event.
+		Str("method", req.Method).
+		Str("url", c.opts.redactor.URL(req.URL)).
+		Dur("duration", duration).
+		Int64("request_size", req.ContentLength).
+		Interface("request_headers", c.opts.redactor.Header(req.Header))
+	c.logBody(event, "request_body", req.Header.Get("Content-Type"), requestBody, requestTruncated)
+	if peekErr != nil {
+		event.AnErr("request_body_error", peekErr)
+	}
+
+	if err != nil {
+		event.Err(err).Msg("http request failed")
+		return nil, err
+	}
+
+	event.
+		Int("status", resp.StatusCode).
+		Int64("response_size", resp.ContentLength).
+		Interface("response_headers", c.opts.redactor.Header(resp.Header))
+	if c.opts.maxBodySize > 0 && !isStreaming(resp.Header.Get("Content-Type")) {
+		var responseBody []byte
+		var responseTruncated bool
+		responseBody, responseTruncated, resp.Body, peekErr = peekBody(resp.Body, c.opts.maxBodySize)
+		c.logBody(event, "response_body", resp.Header.Get("Content-Type"), responseBody, responseTruncated)
+		if peekErr != nil {
+			event.AnErr("response_body_error", peekErr)
+		}
+	}
+	event.Msg("http request")
+
+	return resp, nil
+}
+
+func NewLoggingRequester(
+	requester Requester,
+	options ...LoggingOption,
+) Requester {
+	opts := &loggingOptions{
+		logger:     logs.Logger,
+		level:      zerolog.InfoLevel,
+		errorLevel: zerolog.WarnLevel,
+		redactor:   DefaultRedactor(),
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	opts.redactor = opts.redactor.compiled()
+	return &loggingRequester{
+		requester: requester,
+		opts:      opts,
+	}
+}
+
+func LoggingMiddleware(options ...LoggingOption) Middleware {
+	return func(requester Requester) Requester {
+		return NewLoggingRequester(requester, options...)
+	}
+}
@@ -0,0 +1,66 @@
+package requester
+
+import (
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+type RequesterFunc func(req *http.Request) (*http.Response, error)
+
+func (f RequesterFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type Middleware func(requester Requester) Requester
+
+// Chain wraps base with the given middlewares, the first one being the outermost.
+func Chain(base Requester, middlewares ...Middleware) Requester {
+	requester := base
+	for idx := len(middlewares) - 1; idx >= 0; idx-- {
+		requester = middlewares[idx](requester)
+	}
+	return requester
+}
+
+func RateLimitMiddleware(rateLimiter *rate.Limiter) Middleware {
+	return func(requester Requester) Requester {
+		return NewRateLimitedRequester(requester, rateLimiter)
+	}
+}
+
+func CircuitBreakerMiddleware(options ...CircuitBreakerOption) Middleware {
+	return func(requester Requester) Requester {
+		return NewCircuitBreakerRequester(requester, options...)
+	}
+}
+
+type RoundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func FromRoundTripper(roundTripper http.RoundTripper) Requester {
+	return RequesterFunc(roundTripper.RoundTrip)
+}
+
+func FromClient(client *http.Client) Requester {
+	return client
+}
+
+func ToRoundTripper(requester Requester) http.RoundTripper {
+	if roundTripper, ok := requester.(http.RoundTripper); ok {
+		return roundTripper
+	}
+	return RoundTripperFunc(requester.Do)
+}
+
+func ToClient(requester Requester) *http.Client {
+	if client, ok := requester.(*http.Client); ok {
+		return client
+	}
+	return &http.Client{
+		Transport: ToRoundTripper(requester),
+	}
+}
@@ -0,0 +1,52 @@
+package requester
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Chain(t *testing.T) {
+	calls := []string{}
+	tag := func(name string) Middleware {
+		return func(requester Requester) Requester {
+			return RequesterFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				return requester.Do(req)
+			})
+		}
+	}
+	base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		calls = append(calls, "base")
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       http.NoBody,
+		}, nil
+	})
+
+	requester := Chain(base, tag("first"), tag("second"))
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	res, err := requester.Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, []string{"first", "second", "base"}, calls)
+}
+
+func Test_ToClient(t *testing.T) {
+	base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusTeapot,
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	})
+
+	client := ToClient(base)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	res, err := FromClient(client).Do(req)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTeapot, res.StatusCode)
+}
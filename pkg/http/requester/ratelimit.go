@@ -0,0 +1,298 @@
+package requester
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// resetEpochThreshold tells unix timestamps apart from delta seconds in reset headers.
+const resetEpochThreshold = 1_000_000_000
+
+func ParseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(date.Sub(now), 0), true
+}
+
+// parseLeadingInt reads values such as "100" or "100, 100;w=60".
+func parseLeadingInt(value string) (int64, bool) {
+	value, _, _ = strings.Cut(value, ",")
+	value, _, _ = strings.Cut(value, ";")
+	number, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || number < 0 {
+		return 0, false
+	}
+	return number, true
+}
+
+func parseReset(value string, now time.Time) (time.Time, bool) {
+	number, ok := parseLeadingInt(value)
+	if !ok {
+		return time.Time{}, false
+	}
+	if number >= resetEpochThreshold {
+		return time.Unix(number, 0), true
+	}
+	return now.Add(time.Duration(number) * time.Second), true
+}
+
+type rateLimitHeaders struct {
+	limit        int64
+	hasLimit     bool
+	remaining    int64
+	hasRemaining bool
+	reset        time.Time
+	hasReset     bool
+	retryAfter   time.Duration
+	hasRetry     bool
+}
+
+func parseRateLimitHeaders(header http.Header, now time.Time) rateLimitHeaders {
+	result := rateLimitHeaders{}
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		if !result.hasLimit {
+			result.limit, result.hasLimit = parseLeadingInt(header.Get(prefix + "Limit"))
+		}
+		if !result.hasRemaining {
+			result.remaining, result.hasRemaining = parseLeadingInt(header.Get(prefix + "Remaining"))
+		}
+		if !result.hasReset {
+			result.reset, result.hasReset = parseReset(header.Get(prefix+"Reset"), now)
+		}
+	}
+	// combined form of the IETF draft: RateLimit: limit=100, remaining=50, reset=30
+	for _, item := range strings.Split(header.Get("RateLimit"), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(item), "=")
+		switch strings.ToLower(key) {
+		case "limit":
+			if !result.hasLimit {
+				result.limit, result.hasLimit = parseLeadingInt(value)
+			}
+		case "remaining", "r":
+			if !result.hasRemaining {
+				result.remaining, result.hasRemaining = parseLeadingInt(value)
+			}
+		case "reset", "t":
+			if !result.hasReset {
+				result.reset, result.hasReset = parseReset(value, now)
+			}
+		}
+	}
+	result.retryAfter, result.hasRetry = ParseRetryAfter(header.Get("Retry-After"), now)
+	return result
+}
+
+type RateLimitState struct {
+	Limit       int64
+	Remaining   int64
+	Reset       time.Time
+	Rate        rate.Limit
+	Burst       int
+	PausedUntil time.Time
+}
+
+type adaptiveRateLimitOptions struct {
+	minRate      rate.Limit
+	maxRate      rate.Limit
+	defaultPause time.Duration
+	now          func() time.Time
+}
+
+type AdaptiveRateLimitOption func(opts *adaptiveRateLimitOptions)
+
+func WithAdaptiveRateBounds(minRate rate.Limit, maxRate rate.Limit) AdaptiveRateLimitOption {
+	return func(opts *adaptiveRateLimitOptions) {
+		opts.minRate = minRate
+		opts.maxRate = maxRate
+	}
+}
+
+// WithAdaptiveDefaultPause is how long callers wait after a 429 without any
+// Retry-After or reset header.
+func WithAdaptiveDefaultPause(pause time.Duration) AdaptiveRateLimitOption {
+	return func(opts *adaptiveRateLimitOptions) {
+		opts.defaultPause = pause
+	}
+}
+
+func WithAdaptiveClock(now func() time.Time) AdaptiveRateLimitOption {
+	return func(opts *adaptiveRateLimitOptions) {
+		opts.now = now
+	}
+}
+
+// AdaptiveRateLimiter can be shared by several requesters talking to the same
+// quota, a 429 seen by any of them pauses all.
+type AdaptiveRateLimiter struct {
+	opts         *adaptiveRateLimitOptions
+	limiter      *rate.Limiter
+	initialRate  rate.Limit
+	initialBurst int
+	lock         sync.Mutex
+	state        RateLimitState
+}
+
+func (l *AdaptiveRateLimiter) State() RateLimitState {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	state := l.state
+	state.Rate = l.limiter.Limit()
+	state.Burst = l.limiter.Burst()
+	return state
+}
+
+func (l *AdaptiveRateLimiter) pause() time.Duration {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.opts.now()
+	if !l.state.Reset.IsZero() && !now.Before(l.state.Reset) {
+		// a new window started, until the next response tells otherwise
+		l.state.Reset = time.Time{}
+		l.limiter.SetLimit(l.initialRate)
+		l.limiter.SetBurst(l.initialBurst)
+	}
+	return l.state.PausedUntil.Sub(now)
+}
+
+func (l *AdaptiveRateLimiter) Wait(ctx context.Context) error {
+	for {
+		pause := l.pause()
+		if pause <= 0 {
+			break
+		}
+		timer := time.NewTimer(pause)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return l.limiter.Wait(ctx)
+}
+
+func (l *AdaptiveRateLimiter) clampRate(limit rate.Limit) rate.Limit {
+	if limit < l.opts.minRate {
+		return l.opts.minRate
+	}
+	if limit > l.opts.maxRate {
+		return l.opts.maxRate
+	}
+	return limit
+}
+
+func (l *AdaptiveRateLimiter) Observe(res *http.Response) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.opts.now()
+	headers := parseRateLimitHeaders(res.Header, now)
+	if headers.hasLimit {
+		l.state.Limit = headers.limit
+	}
+	if headers.hasRemaining {
+		l.state.Remaining = headers.remaining
+	}
+	if headers.hasReset {
+		l.state.Reset = headers.reset
+	}
+
+	throttled := res.StatusCode == http.StatusTooManyRequests
+	if throttled ||
+		(headers.hasRetry && res.StatusCode == http.StatusServiceUnavailable) ||
+		(headers.hasRemaining && headers.remaining == 0) {
+		var until time.Time
+		switch {
+		case headers.hasRetry:
+			until = now.Add(headers.retryAfter)
+		case headers.hasReset:
+			until = headers.reset
+		case throttled:
+			until = now.Add(l.opts.defaultPause)
+		}
+		if until.After(l.state.PausedUntil) {
+			l.state.PausedUntil = until
+		}
+		return
+	}
+
+	if headers.hasRemaining && headers.hasReset {
+		window := headers.reset.Sub(now)
+		if window <= 0 {
+			return
+		}
+		l.limiter.SetLimit(l.clampRate(rate.Limit(float64(headers.remaining) / window.Seconds())))
+		l.limiter.SetBurst(int(max(min(headers.remaining, int64(l.initialBurst)), 1)))
+	}
+}
+
+func NewAdaptiveRateLimiter(
+	initialRate rate.Limit,
+	burst int,
+	options ...AdaptiveRateLimitOption,
+) *AdaptiveRateLimiter {
+	opts := &adaptiveRateLimitOptions{
+		maxRate:      rate.Inf,
+		defaultPause: time.Second,
+		now:          time.Now,
+	}
+	for _, option := range options {
+		option(opts)
+	}
+	return &AdaptiveRateLimiter{
+		opts:         opts,
+		limiter:      rate.NewLimiter(initialRate, burst),
+		initialRate:  initialRate,
+		initialBurst: burst,
+	}
+}
+
+type adaptiveRateLimitedRequester struct {
+	requester Requester
+	limiter   *AdaptiveRateLimiter
+}
+
+func (c *adaptiveRateLimitedRequester) Do(req *http.Request) (*http.Response, error) {
+	err := c.limiter.Wait(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.requester.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	c.limiter.Observe(res)
+	return res, nil
+}
+
+func NewAdaptiveRateLimitedRequester(
+	requester Requester,
+	limiter *AdaptiveRateLimiter,
+) Requester {
+	return &adaptiveRateLimitedRequester{
+		requester: requester,
+		limiter:   limiter,
+	}
+}
+
+func AdaptiveRateLimitMiddleware(limiter *AdaptiveRateLimiter) Middleware {
+	return func(requester Requester) Requester {
+		return NewAdaptiveRateLimitedRequester(requester, limiter)
+	}
+}
@@ -0,0 +1,172 @@
+package requester
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vitorsss/go-helpers/pkg/assertutil"
+	"golang.org/x/time/rate"
+)
+
+func Test_ParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{
+			name:     "should parse delay seconds",
+			value:    "120",
+			expected: 2 * time.Minute,
+			ok:       true,
+		},
+		{
+			name:     "should parse http date",
+			value:    now.Add(30 * time.Second).Format(http.TimeFormat),
+			expected: 30 * time.Second,
+			ok:       true,
+		},
+		{
+			name:  "should ignore invalid value",
+			value: "soon",
+		},
+		{
+			name: "should ignore empty value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := ParseRetryAfter(tt.value, now)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func Test_AdaptiveRateLimiter_Observe(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		status   int
+		header   http.Header
+		expected RateLimitState
+	}{
+		{
+			name:   "should follow x-ratelimit headers",
+			status: http.StatusOK,
+			header: http.Header{
+				"X-Ratelimit-Limit":     []string{"100"},
+				"X-Ratelimit-Remaining": []string{"50"},
+				"X-Ratelimit-Reset":     []string{"10"},
+			},
+			expected: RateLimitState{
+				Limit:     100,
+				Remaining: 50,
+				Reset:     now.Add(10 * time.Second),
+				Rate:      5,
+				Burst:     10,
+			},
+		},
+		{
+			name:   "should follow ietf draft headers with epoch reset",
+			status: http.StatusOK,
+			header: http.Header{
+				"Ratelimit-Limit":     []string{"100, 100;w=60"},
+				"Ratelimit-Remaining": []string{"4"},
+				"Ratelimit-Reset":     []string{"1704067202"},
+			},
+			expected: RateLimitState{
+				Limit:     100,
+				Remaining: 4,
+				Reset:     time.Unix(1704067202, 0),
+				Rate:      2,
+				Burst:     4,
+			},
+		},
+		{
+			name:   "should pause after too many requests",
+			status: http.StatusTooManyRequests,
+			header: http.Header{
+				"Retry-After": []string{"30"},
+			},
+			expected: RateLimitState{
+				Rate:        1,
+				Burst:       10,
+				PausedUntil: now.Add(30 * time.Second),
+			},
+		},
+		{
+			name:   "should pause until reset when quota is exhausted",
+			status: http.StatusOK,
+			header: http.Header{
+				"Ratelimit": []string{"limit=10, remaining=0, reset=20"},
+			},
+			expected: RateLimitState{
+				Limit:       10,
+				Reset:       now.Add(20 * time.Second),
+				Rate:        1,
+				Burst:       10,
+				PausedUntil: now.Add(20 * time.Second),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewAdaptiveRateLimiter(1, 10, WithAdaptiveClock(func() time.Time {
+				return now
+			}))
+			limiter.Observe(&http.Response{
+				StatusCode: tt.status,
+				Header:     tt.header,
+			})
+			state := limiter.State()
+			assert.True(t, tt.expected.Reset.Equal(state.Reset))
+			assert.True(t, tt.expected.PausedUntil.Equal(state.PausedUntil))
+			state.Reset = tt.expected.Reset
+			state.PausedUntil = tt.expected.PausedUntil
+			assert.Equal(t, tt.expected, state)
+		})
+	}
+}
+
+func Test_AdaptiveRateLimitedRequester_Pause(t *testing.T) {
+	now := time.Now()
+	limiter := NewAdaptiveRateLimiter(rate.Inf, 1, WithAdaptiveClock(func() time.Time {
+		return now
+	}))
+	calls := 0
+	base := RequesterFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{"60"}},
+			Body:       io.NopCloser(strings.NewReader("")),
+		}, nil
+	})
+	first := NewAdaptiveRateLimitedRequester(base, limiter)
+	second := NewAdaptiveRateLimitedRequester(base, limiter)
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	_, err := first.Do(req)
+	assertutil.Error(t, nil, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = second.Do(req.WithContext(ctx))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, calls)
+
+	now = now.Add(time.Minute)
+	_, err = second.Do(req)
+	assertutil.Error(t, nil, err)
+	assert.Equal(t, 2, calls)
+}
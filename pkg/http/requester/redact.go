@@ -0,0 +1,164 @@
+package requester
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+type Redactor struct {
+	Headers []string
+	// Fields are matched case insensitively against JSON keys, form fields and query params.
+	Fields []string
+
+	fieldRegexes []*regexp.Regexp
+}
+
+func DefaultRedactor() *Redactor {
+	return &Redactor{
+		Headers: []string{
+			"Authorization",
+			"Proxy-Authorization",
+			"Cookie",
+			"Set-Cookie",
+			"X-Api-Key",
+		},
+		Fields: []string{
+			"password",
+			"secret",
+			"client_secret",
+			"token",
+			"access_token",
+			"refresh_token",
+			"api_key",
+		},
+	}
+}
+
+// compiled returns a copy of the redactor with the field patterns compiled
+// once, so the requesters using it do not compile them on every body.
+func (r *Redactor) compiled() *Redactor {
+	if r == nil {
+		return nil
+	}
+	result := *r
+	result.fieldRegexes = make([]*regexp.Regexp, 0, len(r.Fields))
+	for _, field := range r.Fields {
+		result.fieldRegexes = append(result.fieldRegexes, regexp.MustCompile(
+			fmt.Sprintf(`(?i)("%s"\s*:\s*)"(?:[^"\\]|\\.)*"?`, regexp.QuoteMeta(field)),
+		))
+	}
+	return &result
+}
+
+func (r *Redactor) isField(key string) bool {
+	for _, field := range r.Fields {
+		if strings.EqualFold(field, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Redactor) Header(header http.Header) http.Header {
+	result := header.Clone()
+	if r == nil || result == nil {
+		return result
+	}
+	for _, key := range r.Headers {
+		if values := result.Values(key); len(values) > 0 {
+			redacted := make([]string, len(values))
+			for idx := range values {
+				redacted[idx] = redactedValue
+			}
+			result[http.CanonicalHeaderKey(key)] = redacted
+		}
+	}
+	return result
+}
+
+func (r *Redactor) values(values url.Values) url.Values {
+	result := url.Values{}
+	for key, items := range values {
+		if r.isField(key) {
+			redacted := make([]string, len(items))
+			for idx := range items {
+				redacted[idx] = redactedValue
+			}
+			result[key] = redacted
+		} else {
+			result[key] = items
+		}
+	}
+	return result
+}
+
+func (r *Redactor) URL(u *url.URL) string {
+	if r == nil || u.RawQuery == "" {
+		return u.Redacted()
+	}
+	redacted := *u
+	redacted.RawQuery = r.values(u.Query()).Encode()
+	return redacted.Redacted()
+}
+
+func (r *Redactor) Body(contentType string, body []byte) []byte {
+	if r == nil || len(r.Fields) == 0 || len(body) == 0 {
+		return body
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/x-www-form-urlencoded" {
+		values, err := url.ParseQuery(string(body))
+		if err == nil {
+			return []byte(r.values(values).Encode())
+		}
+		return body
+	}
+	if mediaType != "" && mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return body
+	}
+
+	var content any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if decoder.Decode(&content) == nil && !decoder.More() {
+		data, err := json.Marshal(r.json(content))
+		if err == nil {
+			return data
+		}
+	}
+	// truncated or invalid documents still get their string fields masked
+	regexes := r.fieldRegexes
+	if regexes == nil {
+		regexes = r.compiled().fieldRegexes
+	}
+	for _, regex := range regexes {
+		body = regex.ReplaceAll(body, []byte(`${1}"`+redactedValue+`"`))
+	}
+	return body
+}
+
+func (r *Redactor) json(content any) any {
+	switch v := content.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if r.isField(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = r.json(item)
+			}
+		}
+	case []any:
+		for idx, item := range v {
+			v[idx] = r.json(item)
+		}
+	}
+	return content
+}